@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"postmortem-generator/report"
+)
+
+// registerPostmortemRoutes mounts the postmortem CRUD endpoints on rg.
+func registerPostmortemRoutes(rg *gin.RouterGroup, store *FileStore) {
+	pm := rg.Group("/postmortems")
+
+	pm.GET("", func(c *gin.Context) {
+		list, err := store.List()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, list)
+	})
+
+	pm.POST("", func(c *gin.Context) {
+		var data report.PostmortemData
+		if err := c.ShouldBindJSON(&data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		created, err := store.Create(data, requestAuthor(c, data))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Location", c.Request.URL.Path+"/"+created.ID)
+		c.JSON(http.StatusCreated, created)
+	})
+
+	pm.GET("/:id", func(c *gin.Context) {
+		found, err := store.Get(c.Param("id"))
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, found)
+	})
+
+	pm.PUT("/:id", func(c *gin.Context) {
+		var data report.PostmortemData
+		if err := c.ShouldBindJSON(&data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updated, err := store.Update(c.Param("id"), data, requestAuthor(c, data))
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, updated)
+	})
+
+	pm.DELETE("/:id", func(c *gin.Context) {
+		if err := store.Delete(c.Param("id")); err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	// Regenerates the report from the stored data, so it never needs re-importing.
+	// Accepts the same format selection as the generate endpoint.
+	pm.GET("/:id/pdf", func(c *gin.Context) {
+		found, err := store.Get(c.Param("id"))
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		data := found.Data
+		data.Status = found.Status
+		if !validatePostmortem(c, data) {
+			return
+		}
+		sendPostmortem(c, data)
+	})
+
+	pm.POST("/:id/transitions", func(c *gin.Context) {
+		var req struct {
+			To      string `json:"to" binding:"required"`
+			By      string `json:"by"`
+			Comment string `json:"comment"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.By == "" {
+			req.By = c.GetHeader("X-Author")
+		}
+		if req.By == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing \"by\" or X-Author header"})
+			return
+		}
+		// Drafts may be incomplete, but nothing invalid goes up for review.
+		if req.To != report.StatusDraft {
+			found, err := store.Get(c.Param("id"))
+			if err != nil {
+				respondStoreError(c, err)
+				return
+			}
+			if !validatePostmortem(c, found.Data) {
+				return
+			}
+		}
+		updated, err := store.Transition(c.Param("id"), req.To, req.By, req.Comment)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, updated)
+	})
+
+	pm.PUT("/:id/approvers", func(c *gin.Context) {
+		var req struct {
+			Approvers []string `json:"approvers"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Approvers == nil {
+			req.Approvers = []string{}
+		}
+		updated, err := store.SetApprovers(c.Param("id"), req.Approvers)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, updated)
+	})
+
+	pm.GET("/:id/revisions", func(c *gin.Context) {
+		list, err := store.Revisions(c.Param("id"))
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, list)
+	})
+
+	pm.GET("/:id/revisions/:rev", func(c *gin.Context) {
+		n, err := strconv.Atoi(c.Param("rev"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision number"})
+			return
+		}
+		rev, err := store.Revision(c.Param("id"), n)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, rev)
+	})
+
+	// Compares two revisions; "to" defaults to the current one and "from" to the one before it.
+	pm.GET("/:id/diff", func(c *gin.Context) {
+		current, err := store.Get(c.Param("id"))
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		to, err := strconv.Atoi(c.DefaultQuery("to", strconv.Itoa(current.Revision)))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid \"to\" revision"})
+			return
+		}
+		from, err := strconv.Atoi(c.DefaultQuery("from", strconv.Itoa(to-1)))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid \"from\" revision"})
+			return
+		}
+		fromRev, err := store.Revision(current.ID, from)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		toRev, err := store.Revision(current.ID, to)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		changes, err := diffPostmortems(fromRev.Data, toRev.Data)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"from":    RevisionInfo{Number: fromRev.Number, Author: fromRev.Author, CreatedAt: fromRev.CreatedAt},
+			"to":      RevisionInfo{Number: toRev.Number, Author: toRev.Author, CreatedAt: toRev.CreatedAt},
+			"changes": changes,
+		})
+	})
+}
+
+// requestAuthor identifies who made a change: the X-Author header when the
+// client sends one, otherwise the postmortem's creator.
+func requestAuthor(c *gin.Context, data report.PostmortemData) string {
+	if author := c.GetHeader("X-Author"); author != "" {
+		return author
+	}
+	return data.Creator
+}
+
+func respondStoreError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrPublished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotApprover):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"postmortem-generator/report"
+)
+
+// maxAssetSize bounds uploaded images.
+const maxAssetSize = 20 << 20
+
+// assets holds the uploaded images postmortems refer to as "asset:<id>".
+var assets = report.AssetStore{Dir: "data/assets"}
+
+// registerAssetRoutes mounts the image upload endpoints on rg.
+func registerAssetRoutes(rg *gin.RouterGroup, store report.AssetStore) {
+	as := rg.Group("/assets")
+
+	// Takes a multipart "file" field. Uploading an image that is already
+	// stored answers 200 with the same ID instead of 201.
+	as.POST("", func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAssetSize+1<<20)
+		header, err := c.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("images are limited to %d MB", maxAssetSize>>20)})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing \"file\" form field"})
+			return
+		}
+		if header.Size > maxAssetSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("images are limited to %d MB", maxAssetSize>>20)})
+			return
+		}
+		f, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		asset, created, err := store.Put(b)
+		if err != nil {
+			if errors.Is(err, report.ErrUnsupportedAsset) {
+				c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, report.ErrImageTooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Location", c.Request.URL.Path+"/"+asset.ID)
+		if created {
+			c.JSON(http.StatusCreated, asset)
+			return
+		}
+		c.JSON(http.StatusOK, asset)
+	})
+
+	// Serves the image itself, e.g. for previews in the editor. Content
+	// never changes under an ID, so it can be cached for good.
+	as.GET("/:id", func(c *gin.Context) {
+		asset, b, err := store.Load(c.Param("id"))
+		if err != nil {
+			if errors.Is(err, report.ErrAssetNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		c.Data(http.StatusOK, asset.MediaType, b)
+	})
+}
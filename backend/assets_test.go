@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"postmortem-generator/report"
+)
+
+const tinyPNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+func uploadRequest(t *testing.T, field string, content []byte) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile(field, "screenshot.png")
+	require.NoError(t, err)
+	fw.Write(content)
+	require.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/assets", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestAssetRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerAssetRoutes(router.Group("/api/v1"), report.AssetStore{Dir: t.TempDir()})
+	png, _ := base64.StdEncoding.DecodeString(tinyPNG)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, uploadRequest(t, "file", png))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var asset report.Asset
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &asset))
+	assert.Equal(t, "asset:"+asset.ID, asset.Ref)
+	assert.Equal(t, "/api/v1/assets/"+asset.ID, w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, uploadRequest(t, "file", png))
+	assert.Equal(t, http.StatusOK, w.Code, "already stored")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/assets/"+asset.ID, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, png, w.Body.Bytes())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, uploadRequest(t, "file", []byte("%PDF-1.4")))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, uploadRequest(t, "image", png))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, uploadRequest(t, "file", make([]byte, maxAssetSize+2<<20)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/assets/"+strings.Repeat("0", 64), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,195 @@
+// Command chronica renders postmortem files (the JSON exported by the web
+// form, or the same shape written in YAML) without running the HTTP server.
+//
+//	chronica -o report.pdf incident.json
+//	chronica -format md incident.yaml > report.md
+//	cat incident.json | chronica -format html -o - -
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"postmortem-generator/report"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "chronica:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("chronica", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: chronica [flags] <postmortem.json|postmortem.yaml|->")
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", "", "output format: pdf, md, html or docx (default: from -o extension, else pdf)")
+	out := fs.String("o", "-", "output file, or - for stdout")
+	lang := fs.String("lang", "", "override the report language (pt or en)")
+	fontDirs := fs.String("fonts", "", "directories of extra .ttf fonts for PDFs, separated by "+string(filepath.ListSeparator)+" (DejaVu Sans is built in)")
+	themesDir := fs.String("themes", "themes", "directory of named themes (<name>.json, .yaml or .yml)")
+	assetsDir := fs.String("assets", "data/assets", "directory of uploaded images, for \"asset:<id>\" references")
+	theme := fs.String("theme", "", "theme name from -themes, or a theme file (default: the postmortem's options.theme)")
+	extractImages := fs.Bool("extract-images", false, "with -format md, write a zip with the document and its images (implied by -o <file>.zip)")
+	skipValidation := fs.Bool("skip-validation", false, "render even if the postmortem has invalid fields")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil // the usage is already printed
+		}
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one input file")
+	}
+	in := fs.Arg(0)
+
+	raw, err := readInput(in, stdin)
+	if err != nil {
+		return err
+	}
+	data, err := decodePostmortem(raw, filepath.Ext(in))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", in, err)
+	}
+	if *lang != "" {
+		data.Lang = *lang
+	}
+	if *theme != "" {
+		if strings.ContainsAny(*theme, `/\`) || filepath.Ext(*theme) != "" {
+			t, err := report.LoadTheme(*theme)
+			if err != nil {
+				return err
+			}
+			data.Options.Theme = report.ThemeRef{Name: t.Name, Theme: t}
+		} else {
+			data.Options.Theme = report.ThemeRef{Name: *theme}
+		}
+	}
+	if err := (report.ThemeStore{Dir: *themesDir}).Resolve(&data, ""); err != nil {
+		return err
+	}
+	if err := (report.AssetStore{Dir: *assetsDir}).Resolve(&data); err != nil {
+		return err
+	}
+	if !*skipValidation {
+		if errs := report.Validate(data); len(errs) > 0 {
+			return fmt.Errorf("%s is invalid: %w", in, errs)
+		}
+	}
+
+	if *format == "" {
+		*format = formatFromPath(*out)
+	}
+	var r report.Renderer
+	switch *format {
+	case report.FormatPDF:
+		lib, err := report.LoadFonts(filepath.SplitList(*fontDirs)...)
+		if err != nil {
+			return err
+		}
+		r = report.PDFRenderer{Fonts: lib}
+	case report.FormatMarkdown:
+		name := strings.TrimSuffix(filepath.Base(*out), filepath.Ext(*out))
+		if *out == "-" {
+			name = ""
+		}
+		// A .zip output can only be the archive.
+		extract := *extractImages || strings.EqualFold(filepath.Ext(*out), ".zip")
+		r = report.MarkdownRenderer{ExtractImages: extract, Name: name}
+	default:
+		if r, err = report.New(*format); err != nil {
+			return err
+		}
+	}
+
+	// Render into memory first so a failed render never leaves a truncated file behind.
+	var buf bytes.Buffer
+	if err := r.Render(context.Background(), data, &buf); err != nil {
+		return err
+	}
+	if *out == "-" {
+		_, err = stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*out, buf.Bytes(), 0o644)
+}
+
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// formatFromPath guesses the output format from the output file extension.
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".zip":
+		return report.FormatMarkdown
+	case ".html", ".htm":
+		return report.FormatHTML
+	case ".docx":
+		return report.FormatDOCX
+	}
+	return report.FormatPDF
+}
+
+// decodePostmortem parses JSON or YAML. YAML is read generically and then
+// re-encoded as JSON, so both formats use the same field names as the web
+// form's "Exportar JSON" file.
+func decodePostmortem(raw []byte, ext string) (report.PostmortemData, error) {
+	var data report.PostmortemData
+	ext = strings.ToLower(ext)
+	if ext != ".yaml" && ext != ".yml" {
+		if err := json.Unmarshal(raw, &data); err == nil || ext == ".json" {
+			return data, err
+		}
+	}
+
+	var generic interface{}
+	if err := yaml.Unmarshal(raw, &generic); err != nil {
+		return data, err
+	}
+	asJSON, err := json.Marshal(normalizeYAML(generic))
+	if err != nil {
+		return data, err
+	}
+	err = json.Unmarshal(asJSON, &data)
+	return data, err
+}
+
+// normalizeYAML turns YAML timestamps back into the plain strings the
+// renderers expect: unquoted dates such as 2025-10-18 become "2025-10-18".
+func normalizeYAML(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, el := range t {
+			t[k] = normalizeYAML(el)
+		}
+	case []interface{}:
+		for i, el := range t {
+			t[i] = normalizeYAML(el)
+		}
+	case time.Time:
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+			return t.Format("2006-01-02")
+		}
+		return t.Format(time.RFC3339)
+	}
+	return v
+}
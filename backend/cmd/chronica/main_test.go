@@ -0,0 +1,93 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"postmortem-generator/report"
+)
+
+const sampleYAML = `
+title: Checkout API Failure
+date: 2025-10-18
+severity: SEV-2
+startTime: "02:22"
+endTime: "03:34"
+rootCause: Incorrect change in Redis TTL
+timeline:
+  - id: t1
+    time: "02:22"
+    actor: PagerDuty
+    notes: Alert fired
+actions:
+  - action: Add TTL test
+    owner: Bob
+    due: 2025-11-01
+lang: en
+`
+
+func TestDecodePostmortemYAML(t *testing.T) {
+	data, err := decodePostmortem([]byte(sampleYAML), ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "Checkout API Failure", data.Title)
+	assert.Equal(t, "2025-10-18", data.Date)
+	assert.Equal(t, "2025-11-01", data.Actions[0].Due)
+	assert.Equal(t, "PagerDuty", data.Timeline[0].Actor)
+	assert.Equal(t, "Incorrect change in Redis TTL", data.RootCause)
+}
+
+func TestRunWritesMarkdownToStdout(t *testing.T) {
+	var stdout bytes.Buffer
+	err := run([]string{"-format", "md", "-"}, strings.NewReader(`{"title":"Checkout API Failure","severity":"SEV-2","lang":"en"}`), &stdout)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stdout.String(), "# Checkout API Failure\n"))
+}
+
+func TestRunInfersFormatFromOutput(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "incident.yml")
+	require.NoError(t, os.WriteFile(in, []byte(sampleYAML), 0o644))
+	out := filepath.Join(dir, "report.html")
+
+	require.NoError(t, run([]string{"-o", out, in}, nil, nil))
+	html, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "<title>Checkout API Failure</title>")
+}
+
+func TestRunHelp(t *testing.T) {
+	assert.NoError(t, run([]string{"-h"}, strings.NewReader(""), &bytes.Buffer{}))
+}
+
+func TestRunWritesZipOutputAsArchive(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "report.zip")
+	require.NoError(t, run([]string{"-o", out, "-"}, strings.NewReader(`{"title":"Checkout API Failure","severity":"SEV-2"}`), nil))
+
+	zr, err := zip.OpenReader(out)
+	require.NoError(t, err, "a .zip output is a zip archive")
+	defer zr.Close()
+	require.NotEmpty(t, zr.File)
+	assert.Equal(t, "report.md", zr.File[0].Name)
+}
+
+func TestRunRejectsUnknownFormat(t *testing.T) {
+	err := run([]string{"-format", "odt", "-"}, strings.NewReader(`{"title":"x","severity":"SEV-3"}`), &bytes.Buffer{})
+	assert.ErrorIs(t, err, report.ErrUnknownFormat)
+}
+
+func TestRunValidatesInput(t *testing.T) {
+	err := run([]string{"-format", "md", "-"}, strings.NewReader(`{"title":"x","severity":"SEV-9"}`), &bytes.Buffer{})
+	var errs report.ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, "severity", errs[0].Field)
+
+	err = run([]string{"-format", "md", "-skip-validation", "-"}, strings.NewReader(`{"title":"x","severity":"SEV-9"}`), &bytes.Buffer{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"postmortem-generator/report"
+)
+
+// FieldChange is a single difference between two versions of a postmortem.
+// Path uses JSON field names, e.g. "lessons.good", "actions[2].status" or
+// "timeline[id=t3].notes" for timeline entries matched by their ID.
+type FieldChange struct {
+	Path string      `json:"path"`
+	Type string      `json:"type"` // added, removed or modified
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// diffPostmortems compares two PostmortemData values field by field.
+func diffPostmortems(from, to report.PostmortemData) ([]FieldChange, error) {
+	a, err := toGeneric(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toGeneric(to)
+	if err != nil {
+		return nil, err
+	}
+	changes := []FieldChange{}
+	diffValues("", a, b, &changes)
+	return changes, nil
+}
+
+// toGeneric round-trips v through JSON so the diff works on the same shape
+// clients send and receive.
+func toGeneric(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(b, &out)
+	return out, err
+}
+
+func diffValues(path string, a, b interface{}, changes *[]FieldChange) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			diffObjects(path, av, bv, changes)
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			diffArrays(path, av, bv, changes)
+			return
+		}
+	}
+	if isEmptyValue(a) && isEmptyValue(b) {
+		return
+	}
+	if isEmptyValue(a) {
+		*changes = append(*changes, FieldChange{Path: path, Type: "added", New: b})
+		return
+	}
+	if isEmptyValue(b) {
+		*changes = append(*changes, FieldChange{Path: path, Type: "removed", Old: a})
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, FieldChange{Path: path, Type: "modified", Old: a, New: b})
+	}
+}
+
+func diffObjects(path string, a, b map[string]interface{}, changes *[]FieldChange) {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		diffValues(joinPath(path, k), a[k], b[k], changes)
+	}
+}
+
+// diffArrays matches elements by their "id" when every element carries one
+// (timeline entries), so reordering or inserting an entry does not show up as
+// a change to every entry after it. Other arrays are compared by index.
+func diffArrays(path string, a, b []interface{}, changes *[]FieldChange) {
+	if hasIDs(a) && hasIDs(b) {
+		index := map[string]interface{}{}
+		for _, el := range b {
+			index[elementID(el)] = el
+		}
+		seen := map[string]bool{}
+		for _, el := range a {
+			id := elementID(el)
+			seen[id] = true
+			diffValues(fmt.Sprintf("%s[id=%s]", path, id), el, index[id], changes)
+		}
+		for _, el := range b {
+			if id := elementID(el); !seen[id] {
+				diffValues(fmt.Sprintf("%s[id=%s]", path, id), nil, el, changes)
+			}
+		}
+		return
+	}
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		var av, bv interface{}
+		if i < len(a) {
+			av = a[i]
+		}
+		if i < len(b) {
+			bv = b[i]
+		}
+		diffValues(fmt.Sprintf("%s[%d]", path, i), av, bv, changes)
+	}
+}
+
+func hasIDs(list []interface{}) bool {
+	for _, el := range list {
+		if elementID(el) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func elementID(el interface{}) string {
+	obj, ok := el.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	id, _ := obj["id"].(string)
+	return id
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	}
+	return false
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
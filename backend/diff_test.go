@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"postmortem-generator/report"
+)
+
+func TestDiffPostmortems(t *testing.T) {
+	from := report.PostmortemData{
+		Title: "Checkout API Failure",
+		Timeline: []report.TimelineEntry{
+			{ID: "t1", Time: "02:22", Notes: "Alert fired"},
+			{ID: "t2", Time: "02:30", Notes: "Rollback"},
+		},
+		Actions: []report.Action{{Action: "Add TTL test", Status: "Open"}},
+	}
+	to := report.PostmortemData{
+		Title: "Checkout API Failure",
+		Timeline: []report.TimelineEntry{
+			{ID: "t0", Time: "02:10", Notes: "Deploy"},
+			{ID: "t1", Time: "02:22", Notes: "Alert fired"},
+			{ID: "t2", Time: "02:30", Notes: "Rollback started"},
+		},
+		Actions: []report.Action{{Action: "Add TTL test", Status: "Done"}},
+		Lessons: report.Lessons{Good: "Fast rollback"},
+	}
+
+	changes, err := diffPostmortems(from, to)
+	require.NoError(t, err)
+
+	byPath := map[string]FieldChange{}
+	for _, ch := range changes {
+		byPath[ch.Path] = ch
+	}
+	assert.Len(t, changes, 4)
+	assert.Equal(t, "modified", byPath["timeline[id=t2].notes"].Type)
+	assert.Equal(t, "Rollback started", byPath["timeline[id=t2].notes"].New)
+	assert.Equal(t, "added", byPath["timeline[id=t0]"].Type)
+	assert.Equal(t, "modified", byPath["actions[0].status"].Type)
+	assert.Equal(t, "added", byPath["lessons.good"].Type)
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+
+	"postmortem-generator/report"
 )
 
 func TestGeneratePDF(t *testing.T) {
@@ -26,3 +28,21 @@ func TestGeneratePDF(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "PDF generated successfully!", w.Body.String())
 }
+
+func TestRequestedFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		url, accept, want string
+	}{
+		{"/generate-postmortem-pdf", "*/*", report.FormatPDF},
+		{"/generate-postmortem-pdf?format=docx", "", report.FormatDOCX},
+		{"/generate-postmortem-pdf", report.DOCXMediaType + ", */*;q=0.8", report.FormatDOCX},
+		{"/generate-postmortem-pdf?format=html", report.DOCXMediaType, report.FormatHTML},
+	}
+	for _, tc := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, tc.url, nil)
+		c.Request.Header.Set("Accept", tc.accept)
+		assert.Equal(t, tc.want, requestedFormat(c), tc.url)
+	}
+}
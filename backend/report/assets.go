@@ -0,0 +1,157 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// AssetRefPrefix starts an image that refers to an uploaded asset, as in
+// "asset:<id>", where a data URL would otherwise go.
+const AssetRefPrefix = "asset:"
+
+// ErrAssetNotFound is returned when an asset ID has no file in the store.
+var ErrAssetNotFound = errors.New("asset not found")
+
+// ErrUnsupportedAsset is returned when an upload is not an image the
+// renderers accept.
+var ErrUnsupportedAsset = errors.New("not a PNG, JPEG, GIF, WebP, BMP or TIFF image")
+
+var assetIDPattern = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// assetMediaTypes maps image.Decode format names to media types.
+var assetMediaTypes = map[string]string{
+	"png":  "image/png",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+	"bmp":  "image/bmp",
+	"tiff": "image/tiff",
+}
+
+// Asset is an uploaded image. Its ID is the SHA-256 of its content, so the
+// same image uploaded twice is stored once.
+type Asset struct {
+	ID        string `json:"id"`
+	Ref       string `json:"ref"` // "asset:<id>", for TimelineEntry.Images and Branding
+	MediaType string `json:"mediaType"`
+	Size      int    `json:"size"` // bytes
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+}
+
+// describeAsset checks that b is an accepted image and describes it.
+func describeAsset(b []byte) (Asset, error) {
+	cfg, format, err := imageConfig(b)
+	if errors.Is(err, ErrImageTooLarge) {
+		return Asset{}, err
+	}
+	mediaType, ok := assetMediaTypes[format]
+	if err != nil || !ok {
+		return Asset{}, ErrUnsupportedAsset
+	}
+	sum := sha256.Sum256(b)
+	id := hex.EncodeToString(sum[:])
+	return Asset{ID: id, Ref: AssetRefPrefix + id, MediaType: mediaType, Size: len(b), Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// isAssetRef reports whether s is a well-formed asset reference.
+func isAssetRef(s string) bool {
+	id, ok := strings.CutPrefix(s, AssetRefPrefix)
+	return ok && assetIDPattern.MatchString(id)
+}
+
+// AssetStore keeps uploaded images as <Dir>/<id>, the file named by the
+// SHA-256 of its content.
+type AssetStore struct {
+	Dir string
+}
+
+// Put stores the image b. created is false when the store already had it.
+func (s AssetStore) Put(b []byte) (asset Asset, created bool, err error) {
+	asset, err = describeAsset(b)
+	if err != nil {
+		return asset, false, err
+	}
+	path := filepath.Join(s.Dir, asset.ID)
+	if _, err := os.Stat(path); err == nil {
+		return asset, false, nil
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return asset, false, err
+	}
+	// Write under a temporary name first so a reader never sees half a file.
+	tmp, err := os.CreateTemp(s.Dir, asset.ID+".*.tmp")
+	if err != nil {
+		return asset, false, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return asset, false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return asset, false, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return asset, false, err
+	}
+	return asset, true, nil
+}
+
+// Load reads the asset with the given ID.
+func (s AssetStore) Load(id string) (Asset, []byte, error) {
+	if !assetIDPattern.MatchString(id) || s.Dir == "" {
+		return Asset{}, nil, fmt.Errorf("%w: %q", ErrAssetNotFound, id)
+	}
+	b, err := os.ReadFile(filepath.Join(s.Dir, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Asset{}, nil, fmt.Errorf("%w: %q", ErrAssetNotFound, id)
+	}
+	if err != nil {
+		return Asset{}, nil, err
+	}
+	asset, err := describeAsset(b)
+	return asset, b, err
+}
+
+// Resolve replaces the asset references in the timeline images and the
+// branding of data with data URLs of the stored images, so renderers only
+// ever see data URLs. The slices of data are copied, not changed in place.
+func (s AssetStore) Resolve(data *PostmortemData) error {
+	resolve := func(ref *string) error {
+		id, ok := strings.CutPrefix(*ref, AssetRefPrefix)
+		if !ok {
+			return nil
+		}
+		asset, b, err := s.Load(id)
+		if err != nil {
+			return err
+		}
+		*ref = "data:" + asset.MediaType + ";base64," + base64.StdEncoding.EncodeToString(b)
+		return nil
+	}
+
+	data.Timeline = append([]TimelineEntry(nil), data.Timeline...)
+	for i := range data.Timeline {
+		images := append([]Image(nil), data.Timeline[i].Images...)
+		for j := range images {
+			if err := resolve(&images[j].Src); err != nil {
+				return err
+			}
+		}
+		data.Timeline[i].Images = images
+	}
+	for _, ref := range []*string{&data.Branding.Logo, &data.Branding.Header, &data.Branding.Footer} {
+		if err := resolve(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
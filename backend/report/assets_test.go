@@ -0,0 +1,63 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetStore(t *testing.T) {
+	store := AssetStore{Dir: t.TempDir()}
+	_, png, err := parseDataURL(tinyPNG)
+	require.NoError(t, err)
+
+	asset, created, err := store.Put(png)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Len(t, asset.ID, 64)
+	assert.Equal(t, "asset:"+asset.ID, asset.Ref)
+	assert.Equal(t, "image/png", asset.MediaType)
+	assert.Equal(t, []int{1, 1}, []int{asset.Width, asset.Height})
+
+	again, created, err := store.Put(png)
+	require.NoError(t, err)
+	assert.False(t, created, "the same content is stored once")
+	assert.Equal(t, asset.ID, again.ID)
+
+	loaded, b, err := store.Load(asset.ID)
+	require.NoError(t, err)
+	assert.Equal(t, asset, loaded)
+	assert.Equal(t, png, b)
+
+	_, _, err = store.Put([]byte("<svg/>"))
+	assert.ErrorIs(t, err, ErrUnsupportedAsset)
+	_, _, err = store.Put(bombPNG())
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+	_, _, err = store.Load(strings.Repeat("0", 64))
+	assert.ErrorIs(t, err, ErrAssetNotFound)
+	_, _, err = store.Load("../secrets")
+	assert.ErrorIs(t, err, ErrAssetNotFound)
+
+	t.Run("resolve", func(t *testing.T) {
+		images := []Image{{Src: asset.Ref, Caption: "Error rate"}, {Src: tinyWebP}}
+		data := PostmortemData{
+			Timeline: []TimelineEntry{{ID: "t1", Images: images}},
+			Branding: Branding{Logo: asset.Ref},
+		}
+		for _, e := range Validate(data) {
+			assert.NotEqual(t, CodeInvalidImage, e.Code, "asset references are valid images: %s", e.Field)
+		}
+
+		require.NoError(t, store.Resolve(&data))
+		assert.Equal(t, tinyPNG, data.Timeline[0].Images[0].Src)
+		assert.Equal(t, "Error rate", data.Timeline[0].Images[0].Caption)
+		assert.Equal(t, tinyWebP, data.Timeline[0].Images[1].Src, "data URLs are left alone")
+		assert.Equal(t, tinyPNG, data.Branding.Logo)
+		assert.Equal(t, asset.Ref, images[0].Src, "the caller's images are not changed")
+
+		data.Branding.Header = "asset:" + strings.Repeat("f", 64)
+		assert.ErrorIs(t, store.Resolve(&data), ErrAssetNotFound)
+	})
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"strings"
+	"time"
+)
+
+// CAPA layouts accepted in Options.CAPALayout.
+const (
+	CAPALayoutCards = "cards" // one block per action (default)
+	CAPALayoutTable = "table" // one row per action, header repeated on every page
+)
+
+// Badge kinds, which decide a badge's color in every format.
+const (
+	badgeNeutral = "neutral"
+	badgeUrgent  = "urgent"  // P1 (or P0) priority
+	badgeBlocked = "blocked" // status says the action is blocked
+	badgeDone    = "done"    // status says the action is finished
+	badgeOverdue = "overdue" // due date passed and the action is not done
+)
+
+// badgeColors are the fill colors of each badge kind; the text is white.
+var badgeColors = map[string]struct{ R, G, B int }{
+	badgeNeutral: {110, 110, 110},
+	badgeUrgent:  {192, 0, 0},
+	badgeBlocked: {222, 120, 0},
+	badgeDone:    {46, 125, 50},
+	badgeOverdue: {156, 0, 110},
+}
+
+// badge is a short colored label such as a priority or status.
+type badge struct {
+	Text string
+	Kind string
+}
+
+// actionBadges describes the priority, status and due date of an action.
+// Fields left blank get no badge; Overdue is only set when it applies.
+type actionBadges struct {
+	Priority *badge
+	Status   *badge
+	Overdue  *badge
+}
+
+var doneStatuses = map[string]bool{
+	"done": true, "closed": true, "completed": true, "resolved": true,
+	"concluído": true, "concluido": true, "concluída": true, "concluida": true, "fechado": true, "feito": true,
+}
+
+var blockedStatuses = map[string]bool{"blocked": true, "bloqueado": true, "bloqueada": true}
+
+// badgesFor classifies an action as of now.
+func badgesFor(a Action, lang string, now time.Time) actionBadges {
+	var b actionBadges
+	if p := strings.TrimSpace(a.Priority); p != "" {
+		kind := badgeNeutral
+		if up := strings.ToUpper(p); up == "P0" || up == "P1" {
+			kind = badgeUrgent
+		}
+		b.Priority = &badge{Text: p, Kind: kind}
+	}
+
+	status := strings.ToLower(strings.TrimSpace(a.Status))
+	if status != "" {
+		kind := badgeNeutral
+		switch {
+		case blockedStatuses[status]:
+			kind = badgeBlocked
+		case doneStatuses[status]:
+			kind = badgeDone
+		}
+		b.Status = &badge{Text: strings.TrimSpace(a.Status), Kind: kind}
+	}
+
+	if due, err := time.Parse("2006-01-02", a.Due); err == nil && !doneStatuses[status] {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if due.Before(today) {
+			b.Overdue = &badge{Text: tr(lang, "Overdue"), Kind: badgeOverdue}
+		}
+	}
+	return b
+}
@@ -0,0 +1,27 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadgesFor(t *testing.T) {
+	now := time.Date(2025, 11, 10, 9, 0, 0, 0, time.UTC)
+
+	b := badgesFor(Action{Priority: "p1", Status: "Blocked", Due: "2025-11-09"}, "en", now)
+	assert.Equal(t, &badge{Text: "p1", Kind: badgeUrgent}, b.Priority)
+	assert.Equal(t, &badge{Text: "Blocked", Kind: badgeBlocked}, b.Status)
+	assert.Equal(t, &badge{Text: "Overdue", Kind: badgeOverdue}, b.Overdue)
+
+	b = badgesFor(Action{Priority: "P3", Status: "Concluído", Due: "2025-11-01"}, "pt", now)
+	assert.Equal(t, badgeNeutral, b.Priority.Kind)
+	assert.Equal(t, badgeDone, b.Status.Kind)
+	assert.Nil(t, b.Overdue, "finished actions are never overdue")
+
+	b = badgesFor(Action{Due: "2025-11-10"}, "pt", now)
+	assert.Nil(t, b.Priority)
+	assert.Nil(t, b.Status)
+	assert.Nil(t, b.Overdue, "due today is not overdue yet")
+}
@@ -0,0 +1,635 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// DOCXMediaType is the MIME type of Word (.docx) documents.
+const DOCXMediaType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// emuPerInch converts inches to the English Metric Units used by DrawingML.
+const emuPerInch = 914400
+
+// docxMedia is an image stored under word/media and referenced by relationship ID.
+type docxMedia struct {
+	RelID string
+	Name  string
+	Data  []byte
+}
+
+// docxPart is one file inside the .docx zip container.
+type docxPart struct {
+	name    string
+	content []byte
+}
+
+// docxWriter accumulates the WordprocessingML body and the media it references.
+type docxWriter struct {
+	body  bytes.Buffer
+	media []docxMedia
+	links []string // external hyperlink targets; index i has relationship ID rIdLink<i+1>
+	lang  string
+	theme Theme
+	page  PageOptions
+
+	footerLeft, footerRight string
+}
+
+// DOCXRenderer writes postmortems as Office Open XML (.docx) documents.
+type DOCXRenderer struct{}
+
+// MediaType implements Renderer.
+func (DOCXRenderer) MediaType() string { return DOCXMediaType }
+
+// Extension implements Renderer.
+func (DOCXRenderer) Extension() string { return ".docx" }
+
+// Render implements Renderer.
+func (DOCXRenderer) Render(ctx context.Context, data PostmortemData, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d := &docxWriter{lang: data.Lang, theme: data.Options.Theme.theme(), page: data.Options.Page}
+	d.footerLeft, d.footerRight = footerText(data, time.Now())
+	times := formatIncidentTimes(data)
+
+	// Cover
+	if data.Branding.Logo != "" {
+		d.image(data.Branding.Logo, 2.5, "center", data.Options.Images)
+	}
+	d.paragraph("Title", data.Title)
+	d.centered(fmt.Sprintf("%s - %s", tr(d.lang, "Post-Incident Report"), times.Date))
+	d.centered(fmt.Sprintf("%s: %s", tr(d.lang, "Severity"), formatSeverity(data.Severity, d.lang)))
+	d.centered(fmt.Sprintf("%s: %s", tr(d.lang, "Creator"), data.Creator))
+	if data.Status != "" {
+		d.centered(fmt.Sprintf("%s: %s", tr(d.lang, "Status"), statusLabel(data.Status, d.lang)))
+	}
+	d.pageBreak()
+
+	d.paragraph("Heading1", tr(d.lang, "Incident Overview"))
+	overview := [][]string{
+		{tr(d.lang, "Date (start)"), times.Date},
+		{tr(d.lang, "Severity"), formatSeverity(data.Severity, d.lang)},
+		{tr(d.lang, "Duration"), times.Duration},
+		{tr(d.lang, "Start"), strings.Join(nonEmpty(times.Start, times.StartUTC), "\n")},
+		{tr(d.lang, "End"), strings.Join(nonEmpty(times.End, times.EndUTC), "\n")},
+	}
+	if data.Timezone != "" {
+		overview = append(overview, []string{tr(d.lang, "Timezone"), data.Timezone})
+	}
+	for _, m := range incidentMetrics(data) {
+		overview = append(overview, []string{fmt.Sprintf("%s (%s)", m.Label, m.Name), m.Value})
+	}
+	d.table([]float64{0.35, 0.65}, false, overview, nil)
+	d.paragraph("", fmt.Sprintf("%s %s", tr(d.lang, "Owners:"), data.Owners))
+	d.paragraph("", fmt.Sprintf("%s %s", tr(d.lang, "Affected Systems:"), data.Affected))
+
+	d.section(tr(d.lang, "Executive Summary"), data.Summary, data.Snippets.Summary)
+	d.section(tr(d.lang, "Customer Impact"), data.Impact, data.Snippets.Impact)
+	d.section(tr(d.lang, "Root Cause"), data.RootCause, data.Snippets.RootCause)
+	d.section(tr(d.lang, "Detection"), data.Detection, data.Snippets.Detection)
+	d.section(tr(d.lang, "Incident Response"), data.Response, data.Snippets.Response)
+	d.section(tr(d.lang, "Communications"), data.Comm, data.Snippets.Comm)
+
+	if len(data.Timeline) > 0 {
+		d.paragraph("Heading1", tr(d.lang, "Timeline"))
+		figures := timelineFigures(data)
+		for i, entry := range data.Timeline {
+			d.paragraph("Heading2", fmt.Sprintf("%s  |  %s %s", entry.Time, tr(d.lang, "Actor:"), entry.Actor))
+			if entry.Notes != "" {
+				d.richText(entry.Notes)
+			}
+			for _, s := range entry.Snippets {
+				d.snippet(s)
+			}
+			d.figures(figures[i])
+		}
+	}
+
+	if len(data.Actions) > 0 {
+		d.paragraph("Heading1", tr(d.lang, "Corrective & Preventive Actions (CAPA)"))
+		rows := [][]string{{tr(d.lang, "Action"), tr(d.lang, "Owner"), tr(d.lang, "Priority"), tr(d.lang, "Due Date"), tr(d.lang, "Status")}}
+		kinds := [][]string{nil}
+		now := time.Now()
+		for _, a := range data.Actions {
+			badges := badgesFor(a, d.lang, now)
+			due := formatDate(a.Due, d.lang)
+			row := make([]string, 5)
+			if badges.Priority != nil && badges.Priority.Kind != badgeNeutral {
+				row[2] = badges.Priority.Kind
+			}
+			if badges.Overdue != nil {
+				due += " (" + badges.Overdue.Text + ")"
+				row[3] = badgeOverdue
+			}
+			if badges.Status != nil && badges.Status.Kind != badgeNeutral {
+				row[4] = badges.Status.Kind
+			}
+			rows = append(rows, []string{a.Action, a.Owner, a.Priority, due, a.Status})
+			kinds = append(kinds, row)
+		}
+		d.table([]float64{0.40, 0.18, 0.12, 0.15, 0.15}, true, rows, kinds)
+	}
+
+	if data.Lessons.Good != "" || data.Lessons.Improve != "" {
+		d.paragraph("Heading1", tr(d.lang, "Lessons Learned"))
+		if data.Lessons.Good != "" {
+			d.paragraph("Heading2", tr(d.lang, "What went well:"))
+			d.richText(data.Lessons.Good)
+		}
+		if data.Lessons.Improve != "" {
+			d.paragraph("Heading2", tr(d.lang, "What to improve:"))
+			d.richText(data.Lessons.Improve)
+		}
+	}
+
+	if refs := parseReferences(data.References); len(refs) > 0 {
+		d.paragraph("Heading1", tr(d.lang, "References & Links"))
+		for _, ref := range refs {
+			d.reference(ref)
+		}
+	}
+
+	return d.writePackage(w)
+}
+
+func (d *docxWriter) section(title, content string, snippets []Snippet) {
+	if !hasContent(content, snippets) {
+		return
+	}
+	d.paragraph("Heading1", title)
+	d.richText(content)
+	for _, s := range snippets {
+		d.snippet(s)
+	}
+}
+
+// richText writes a narrative field's Markdown subset. Headings are bold
+// paragraphs kept with the next one rather than outline levels, lists are
+// indented paragraphs with their marker, and code blocks are written as
+// snippets.
+func (d *docxWriter) richText(text string) {
+	for _, b := range parseRichText(text) {
+		switch b.Kind {
+		case blockHeading:
+			d.body.WriteString(`<w:p><w:pPr><w:keepNext/><w:spacing w:before="120" w:after="60"/></w:pPr>`)
+			for _, s := range b.Spans {
+				s.Bold = true
+				d.span(s)
+			}
+			d.body.WriteString("</w:p>")
+		case blockBullet, blockNumbered:
+			marker := "•"
+			if b.Kind == blockNumbered {
+				marker = fmt.Sprintf("%d.", b.Number)
+			}
+			fmt.Fprintf(&d.body, `<w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:spacing w:after="60"/><w:ind w:left="%d" w:hanging="360"/></w:pPr>`, 360*(b.Level+1))
+			d.runs(marker, false, "")
+			d.body.WriteString("<w:r><w:tab/></w:r>")
+			for _, s := range b.Spans {
+				d.span(s)
+			}
+			d.body.WriteString("</w:p>")
+		case blockCode:
+			d.snippet(Snippet{Lang: b.Lang, Code: b.Code})
+		default:
+			d.body.WriteString("<w:p>")
+			for _, s := range b.Spans {
+				d.span(s)
+			}
+			d.body.WriteString("</w:p>")
+		}
+	}
+}
+
+// snippet writes a snippet as a shaded paragraph in the theme's mono font,
+// one line break per line, after its title. Line numbers are muted runs and
+// diff lines are shaded runs in their colors; Word wraps long lines.
+func (d *docxWriter) snippet(s Snippet) {
+	palette := d.theme.Palette
+	small := halfPoints(d.theme.Sizes.Small)
+	if s.Title != "" {
+		d.body.WriteString(`<w:p><w:pPr><w:keepNext/><w:spacing w:after="40"/></w:pPr>`)
+		d.run(s.Title, fmt.Sprintf(`<w:b/><w:color w:val="%s"/><w:sz w:val="%d"/>`, palette.Muted.hex(), small))
+		d.body.WriteString("</w:p>")
+	}
+
+	mono := fmt.Sprintf(`<w:rFonts w:ascii="%[1]s" w:hAnsi="%[1]s" w:cs="%[1]s"/>`, xmlAttr(d.theme.Fonts.Mono))
+	lines := s.lines()
+	digits := len(fmt.Sprint(len(lines)))
+	fmt.Fprintf(&d.body, `<w:p><w:pPr><w:shd w:val="clear" w:color="auto" w:fill="%s"/><w:spacing w:line="240" w:lineRule="auto"/></w:pPr>`, codeShade.hex())
+	for i, line := range lines {
+		if i > 0 {
+			d.body.WriteString("<w:r><w:br/></w:r>")
+		}
+		if s.LineNumbers {
+			d.run(fmt.Sprintf("%*d  ", digits, line.Number), fmt.Sprintf(`%s<w:color w:val="%s"/><w:sz w:val="%d"/>`, mono, palette.Muted.hex(), small))
+		}
+		color, shade := palette.Text.hex(), ""
+		switch line.Kind {
+		case lineAdded, lineRemoved:
+			c := snippetLineColors[line.Kind]
+			color, shade = c[1].hex(), fmt.Sprintf(`<w:shd w:val="clear" w:color="auto" w:fill="%s"/>`, c[0].hex())
+		case lineHunk:
+			color = palette.Accent.hex()
+		case lineHeader:
+			color = palette.Muted.hex()
+		}
+		d.run(line.Text, fmt.Sprintf(`%s<w:color w:val="%s"/><w:sz w:val="%d"/>%s`, mono, color, small, shade))
+	}
+	d.body.WriteString("</w:p>")
+}
+
+// span writes inline text with its formatting; links become external
+// hyperlinks.
+func (d *docxWriter) span(s richSpan) {
+	var props strings.Builder
+	if s.Code {
+		fmt.Fprintf(&props, `<w:rFonts w:ascii="%[1]s" w:hAnsi="%[1]s" w:cs="%[1]s"/>`, xmlAttr(d.theme.Fonts.Mono))
+	}
+	if s.Bold {
+		props.WriteString("<w:b/>")
+	}
+	if s.Italic {
+		props.WriteString("<w:i/>")
+	}
+	if s.URL != "" {
+		fmt.Fprintf(&props, `<w:color w:val="%s"/><w:u w:val="single"/>`, d.theme.Palette.Accent.hex())
+		d.links = append(d.links, s.URL)
+		fmt.Fprintf(&d.body, `<w:hyperlink r:id="rIdLink%d">`, len(d.links))
+		d.run(s.Text, props.String())
+		d.body.WriteString("</w:hyperlink>")
+		return
+	}
+	d.run(s.Text, props.String())
+}
+
+// paragraph writes text with the given paragraph style; newlines become line breaks.
+func (d *docxWriter) paragraph(style, text string) {
+	d.body.WriteString("<w:p>")
+	if style != "" {
+		fmt.Fprintf(&d.body, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+	}
+	d.runs(text, false, "")
+	d.body.WriteString("</w:p>")
+}
+
+func (d *docxWriter) centered(text string) {
+	d.body.WriteString(`<w:p><w:pPr><w:jc w:val="center"/></w:pPr>`)
+	d.runs(text, false, "")
+	d.body.WriteString("</w:p>")
+}
+
+// runs writes text as a single run, turning newlines into line breaks.
+func (d *docxWriter) runs(text string, bold bool, color string) {
+	var props string
+	if bold {
+		props += "<w:b/>"
+	}
+	if color != "" {
+		props += fmt.Sprintf(`<w:color w:val="%s"/>`, color)
+	}
+	d.run(text, props)
+}
+
+// run writes text as a run with the given run properties (the inside of
+// w:rPr), turning newlines into line breaks.
+func (d *docxWriter) run(text, props string) {
+	d.body.WriteString("<w:r>")
+	if props != "" {
+		d.body.WriteString("<w:rPr>" + props + "</w:rPr>")
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			d.body.WriteString("<w:br/>")
+		}
+		d.body.WriteString(`<w:t xml:space="preserve">`)
+		xml.EscapeText(&d.body, []byte(line))
+		d.body.WriteString("</w:t>")
+	}
+	d.body.WriteString("</w:r>")
+}
+
+// reference writes one numbered appendix entry, with its label as an
+// external hyperlink when it has a URL.
+func (d *docxWriter) reference(ref reference) {
+	d.body.WriteString(`<w:p><w:pPr><w:pStyle w:val="ListParagraph"/></w:pPr>`)
+	d.runs(ref.Cite()+" ", true, "")
+	if ref.URL == "" {
+		d.runs(ref.Label, false, "")
+	} else {
+		d.links = append(d.links, ref.URL)
+		fmt.Fprintf(&d.body, `<w:hyperlink r:id="rIdLink%d">`, len(d.links))
+		d.runs(ref.Label, false, d.theme.Palette.Accent.hex())
+		d.body.WriteString("</w:hyperlink>")
+		if ref.Label != ref.URL {
+			d.runs("\n"+ref.URL, false, d.theme.Palette.Muted.hex())
+		}
+	}
+	d.body.WriteString("</w:p>")
+}
+
+func (d *docxWriter) pageBreak() {
+	d.body.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+}
+
+// textWidth is the width between the page margins, in twips.
+func (d *docxWriter) textWidth() int {
+	m := d.theme.Margins
+	return twips(d.page.paper().W - m.Left - m.Right)
+}
+
+// table writes a bordered table. ratios split the text width between
+// columns. When header is true the first row is shaded and repeated on every
+// page; otherwise the first column is shaded as a label column, like the
+// overview grid in the PDF. kinds, when given, shades cell [i][j] with the
+// color of that badge kind, as the CAPA table does for P1, blocked and overdue.
+func (d *docxWriter) table(ratios []float64, header bool, rows [][]string, kinds [][]string) {
+	textWidthTwips := float64(d.textWidth())
+	d.body.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr><w:tblGrid>`)
+	for _, r := range ratios {
+		fmt.Fprintf(&d.body, `<w:gridCol w:w="%d"/>`, int(r*textWidthTwips))
+	}
+	d.body.WriteString("</w:tblGrid>")
+	for i, row := range rows {
+		d.body.WriteString("<w:tr>")
+		if header && i == 0 {
+			d.body.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+		}
+		for j, cell := range row {
+			fill := ""
+			if (header && i == 0) || (!header && j == 0) {
+				fill = d.theme.Palette.Primary.hex()
+			} else if i < len(kinds) && j < len(kinds[i]) && kinds[i][j] != "" {
+				c := badgeColors[kinds[i][j]]
+				fill = fmt.Sprintf("%02X%02X%02X", c.R, c.G, c.B)
+			}
+			shaded := fill != ""
+			fmt.Fprintf(&d.body, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>`, int(ratios[j]*textWidthTwips))
+			if shaded {
+				fmt.Fprintf(&d.body, `<w:shd w:val="clear" w:color="auto" w:fill="%s"/>`, fill)
+			}
+			d.body.WriteString("</w:tcPr><w:p>")
+			if shaded {
+				d.runs(cell, true, "FFFFFF")
+			} else {
+				d.runs(cell, false, "")
+			}
+			d.body.WriteString("</w:p></w:tc>")
+		}
+		d.body.WriteString("</w:tr>")
+	}
+	d.body.WriteString("</w:tbl>")
+	d.body.WriteString("<w:p/>")
+}
+
+// image embeds a data URL image, scaled down to at most maxInches wide.
+func (d *docxWriter) image(dataURL string, maxInches float64, align string, opts ImageOptions) {
+	img, err := loadImage(dataURL, maxInches*25.4, opts)
+	if err != nil {
+		return
+	}
+
+	// Assume 96 DPI, the usual for screenshots, and never upscale.
+	widthIn := float64(img.W) / 96
+	if widthIn > maxInches {
+		widthIn = maxInches
+	}
+	cx := int64(widthIn * emuPerInch)
+	cy := cx * int64(img.H) / int64(img.W)
+
+	fmt.Fprintf(&d.body, `<w:p><w:pPr><w:jc w:val="%s"/></w:pPr>`, align)
+	d.drawing(img.Data, imageExtension(img.MimeType), cx, cy)
+	d.body.WriteString("</w:p>")
+}
+
+// drawing writes a run with an inline picture of cx by cy EMUs, adding the
+// image bytes to the media parts.
+func (d *docxWriter) drawing(decoded []byte, ext string, cx, cy int64) {
+	n := len(d.media) + 1
+	m := docxMedia{RelID: fmt.Sprintf("rIdImg%d", n), Name: fmt.Sprintf("image%d%s", n, ext), Data: decoded}
+	d.media = append(d.media, m)
+
+	fmt.Fprintf(&d.body, `<w:r><w:drawing>`+
+		`<wp:inline distT="0" distB="0" distL="0" distR="0"><wp:extent cx="%d" cy="%d"/><wp:docPr id="%d" name="%s"/>`+
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">`+
+		`<a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:nvPicPr><pic:cNvPr id="%d" name="%s"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="%s"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r>`,
+		cx, cy, n, m.Name, n, m.Name, m.RelID, cx, cy)
+}
+
+// figures writes the figures of a timeline entry. A lone figure is a
+// picture paragraph kept with its caption; a row of the grid is a
+// borderless table row that Word does not split across pages.
+func (d *docxWriter) figures(figures []figure) {
+	m := d.theme.Margins
+	paper := d.page.paper()
+	textW, textH := paper.W-m.Left-m.Right, paper.H-m.Top-m.Bottom
+	for _, row := range figureRows(figures) {
+		if len(row) == 1 {
+			d.figure(row[0], figureWidth(row[0].fraction(), textW), textH)
+			continue
+		}
+		d.body.WriteString(`<w:tbl><w:tblPr><w:tblW w:w="5000" w:type="pct"/><w:tblLayout w:type="fixed"/></w:tblPr><w:tblGrid>`)
+		for _, f := range row {
+			fmt.Fprintf(&d.body, `<w:gridCol w:w="%d"/>`, twips(f.fraction()*textW))
+		}
+		d.body.WriteString(`</w:tblGrid><w:tr><w:trPr><w:cantSplit/></w:trPr>`)
+		for _, f := range row {
+			fmt.Fprintf(&d.body, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/></w:tcPr>`, twips(f.fraction()*textW))
+			d.figure(f, figureWidth(f.fraction(), textW), textH)
+			d.body.WriteString("</w:tc>")
+		}
+		d.body.WriteString("</w:tr></w:tbl><w:p/>")
+	}
+}
+
+// figure writes f at 96 DPI, scaled down to fit maxW by maxH millimeters
+// with room for its caption, centered above the caption.
+func (d *docxWriter) figure(f figure, maxW, maxH float64) {
+	w := float64(f.W) / 96 * 25.4
+	if w > maxW {
+		w = maxW
+	}
+	h := w * float64(f.H) / float64(f.W)
+	if maxH -= 15; h > maxH {
+		w, h = w*maxH/h, maxH
+	}
+	cx, cy := int64(w/25.4*emuPerInch), int64(h/25.4*emuPerInch)
+
+	d.body.WriteString(`<w:p><w:pPr><w:keepNext/><w:spacing w:after="60"/><w:jc w:val="center"/></w:pPr>`)
+	d.drawing(f.Data, imageExtension(f.MimeType), cx, cy)
+	d.body.WriteString(`</w:p><w:p><w:pPr><w:jc w:val="center"/></w:pPr>`)
+	d.run(f.label(d.lang), fmt.Sprintf(`<w:i/><w:color w:val="%s"/><w:sz w:val="%d"/>`, d.theme.Palette.Muted.hex(), halfPoints(d.theme.Sizes.Small)))
+	d.body.WriteString("</w:p>")
+}
+
+// footer is the footer part shown on every page but the cover (titlePg):
+// classification and incident ID, "Page X of Y" and the generation time,
+// separated by center and right tab stops like the PDF footer.
+func (d *docxWriter) footer() []byte {
+	var b bytes.Buffer
+	small := halfPoints(d.theme.Sizes.Small)
+	text := func(s string) {
+		fmt.Fprintf(&b, `<w:r><w:rPr><w:color w:val="%s"/><w:sz w:val="%d"/></w:rPr><w:t xml:space="preserve">`, d.theme.Palette.Muted.hex(), small)
+		xml.EscapeText(&b, []byte(s))
+		b.WriteString(`</w:t></w:r>`)
+	}
+	field := func(instr string) {
+		fmt.Fprintf(&b, `<w:fldSimple w:instr=" %s "><w:r><w:rPr><w:sz w:val="%d"/></w:rPr><w:t>1</w:t></w:r></w:fldSimple>`, instr, small)
+	}
+	tab := func() { b.WriteString(`<w:r><w:tab/></w:r>`) }
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		fmt.Sprintf(`<w:p><w:pPr><w:tabs><w:tab w:val="center" w:pos="%d"/><w:tab w:val="right" w:pos="%d"/></w:tabs><w:spacing w:after="0"/></w:pPr>`, d.textWidth()/2, d.textWidth()))
+	text(d.footerLeft)
+	tab()
+	text(tr(d.lang, "Page") + " ")
+	field("PAGE")
+	text(" " + tr(d.lang, "of") + " ")
+	field("NUMPAGES")
+	tab()
+	text(d.footerRight)
+	b.WriteString(`</w:p></w:ftr>`)
+	return b.Bytes()
+}
+
+// writePackage zips the document parts into a .docx container.
+func (d *docxWriter) writePackage(w io.Writer) error {
+	lang := "en-US"
+	if d.lang == "pt" {
+		lang = "pt-BR"
+	}
+
+	var rels strings.Builder
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`<Relationship Id="rIdFooter" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>`)
+	for _, m := range d.media {
+		fmt.Fprintf(&rels, `<Relationship Id="%s" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/%s"/>`, m.RelID, m.Name)
+	}
+	for i, link := range d.links {
+		fmt.Fprintf(&rels, `<Relationship Id="rIdLink%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="`, i+1)
+		xml.EscapeText(&rels, []byte(link))
+		rels.WriteString(`" TargetMode="External"/>`)
+	}
+	rels.WriteString(`</Relationships>`)
+
+	m, paper := d.theme.Margins, d.page.paper()
+	orient := ""
+	if d.page.landscape() {
+		orient = ` w:orient="landscape"`
+	}
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing">` +
+		`<w:body>` + d.body.String() +
+		fmt.Sprintf(`<w:sectPr><w:footerReference w:type="default" r:id="rIdFooter"/><w:pgSz w:w="%d" w:h="%d"%s/><w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="567" w:footer="567" w:gutter="0"/><w:titlePg/></w:sectPr>`,
+			twips(paper.W), twips(paper.H), orient, twips(m.Top), twips(m.Right), twips(m.Bottom), twips(m.Left)) +
+		`</w:body></w:document>`
+
+	parts := []docxPart{
+		{"[Content_Types].xml", []byte(docxContentTypes)},
+		{"_rels/.rels", []byte(docxPackageRels)},
+		{"word/document.xml", []byte(document)},
+		{"word/styles.xml", d.styles(lang)},
+		{"word/_rels/document.xml.rels", []byte(rels.String())},
+		{"word/footer1.xml", d.footer()},
+	}
+	for _, m := range d.media {
+		parts = append(parts, docxPart{"word/media/" + m.Name, m.Data})
+	}
+
+	zw := zip.NewWriter(w)
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(p.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+	`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Default Extension="png" ContentType="image/png"/>` +
+	`<Default Extension="jpg" ContentType="image/jpeg"/>` +
+	`<Default Extension="gif" ContentType="image/gif"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/word/footer1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"/>` +
+	`</Types>`
+
+const docxPackageRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`</Relationships>`
+
+// twips converts millimeters to twentieths of a point.
+func twips(mm float64) int {
+	return int(math.Round(mm * 1440 / 25.4))
+}
+
+// halfPoints converts a font size to the half-points w:sz is measured in.
+func halfPoints(pt float64) int {
+	return int(math.Round(pt * 2))
+}
+
+// styles fills docxStyles from the theme for the given document language.
+func (d *docxWriter) styles(lang string) []byte {
+	t := d.theme
+	family := xmlAttr(t.Fonts.Family)
+	return []byte(fmt.Sprintf(docxStyles,
+		lang, family, halfPoints(t.Sizes.Body), t.Palette.Text.hex(),
+		int(math.Round(276*t.LineSpacing)),
+		halfPoints(t.Sizes.Title),
+		t.Palette.Primary.hex(), halfPoints(t.Sizes.Section),
+		t.Palette.Accent.hex(), halfPoints(t.Sizes.Subsection),
+		t.Palette.Rule.hex(),
+	))
+}
+
+// xmlAttr escapes s for use inside a double-quoted XML attribute.
+func xmlAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// docxStyles mirrors the PDF typography: the theme's font, body size, section
+// heading size and colors. The verbs are filled in by docxWriter.styles.
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+	`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="%[2]s" w:hAnsi="%[2]s" w:cs="%[2]s"/><w:color w:val="%[4]s"/><w:sz w:val="%[3]d"/><w:lang w:val="%[1]s"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="%[5]d" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:before="2400" w:after="480"/><w:jc w:val="center"/></w:pPr><w:rPr><w:b/><w:sz w:val="%[6]d"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="360" w:after="120"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:color w:val="%[7]s"/><w:sz w:val="%[8]d"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="60"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:color w:val="%[9]s"/><w:sz w:val="%[10]d"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="ListParagraph"><w:name w:val="List Paragraph"/><w:basedOn w:val="Normal"/><w:pPr><w:ind w:left="360"/></w:pPr></w:style>` +
+	`<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:tblPr><w:tblBorders>` +
+	`<w:top w:val="single" w:sz="4" w:space="0" w:color="%[11]s"/><w:left w:val="single" w:sz="4" w:space="0" w:color="%[11]s"/>` +
+	`<w:bottom w:val="single" w:sz="4" w:space="0" w:color="%[11]s"/><w:right w:val="single" w:sz="4" w:space="0" w:color="%[11]s"/>` +
+	`<w:insideH w:val="single" w:sz="4" w:space="0" w:color="%[11]s"/><w:insideV w:val="single" w:sz="4" w:space="0" w:color="%[11]s"/>` +
+	`</w:tblBorders><w:tblCellMar><w:left w:w="85" w:type="dxa"/><w:right w:w="85" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>` +
+	`</w:styles>`
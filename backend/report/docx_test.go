@@ -0,0 +1,74 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePostmortemDOCX(t *testing.T) {
+	data := PostmortemData{
+		Title:    "Checkout & API <Failure>",
+		Timeline: []TimelineEntry{{ID: "t1", Time: "02:22", Actor: "SRE", Notes: "line 1\nline 2", Images: []Image{{Src: tinyPNG}}}},
+		Actions:  []Action{{Action: "Add TTL test", Owner: "Bob", Priority: "P1", Status: "Open"}},
+		Lang:     "en",
+		Options:  Options{Footer: FooterOptions{Classification: "Confidential", IncidentID: "INC-42"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, DOCXRenderer{}.Render(context.Background(), data, &buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/document.xml", "word/styles.xml", "word/_rels/document.xml.rels", "word/footer1.xml", "word/media/image1.png"} {
+		assert.Contains(t, files, name)
+	}
+
+	rc, err := files["word/document.xml"].Open()
+	require.NoError(t, err)
+	doc, err := io.ReadAll(rc)
+	require.NoError(t, err)
+
+	// The document must be well-formed XML with the title escaped.
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else {
+			require.NoError(t, err)
+		}
+	}
+	assert.Contains(t, string(doc), "Checkout &amp; API &lt;Failure&gt;")
+	assert.Contains(t, string(doc), `r:embed="rIdImg1"`)
+	assert.Contains(t, string(doc), "<w:tblHeader/>")
+
+	rc, err = files["word/footer1.xml"].Open()
+	require.NoError(t, err)
+	footer, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Contains(t, string(footer), "Confidential · INC-42")
+	assert.Contains(t, string(footer), `w:instr=" NUMPAGES "`)
+}
+
+// readDOCXPart returns the named part of a .docx file.
+func readDOCXPart(t *testing.T, docx []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(docx), int64(len(docx)))
+	require.NoError(t, err)
+	rc, err := zr.Open(name)
+	require.NoError(t, err)
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return string(b)
+}
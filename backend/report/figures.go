@@ -0,0 +1,132 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Image.Size values: the share of the text width an image takes. Images
+// narrower than full share a row with the ones next to them.
+const (
+	ImageFull  = "full" // default
+	ImageHalf  = "half"
+	ImageThird = "third"
+)
+
+// figureGap is the space between the images of a row and below each row,
+// in millimeters.
+const figureGap = 4.0
+
+// Image is a picture attached to a timeline entry. In JSON it is either a
+// data URL string, as in earlier versions, or an object.
+type Image struct {
+	Src     string `json:"src"`               // data URL, or "asset:<id>" for an uploaded image
+	Caption string `json:"caption,omitempty"` // printed under the image, after its figure number
+	Alt     string `json:"alt,omitempty"`     // text alternative; defaults to the caption
+	Size    string `json:"size,omitempty"`    // "full", "half" or "third"
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (img *Image) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		*img = Image{}
+		return json.Unmarshal(b, &img.Src)
+	}
+	type plain Image
+	var p plain
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*img = Image(p)
+	return nil
+}
+
+// fraction is the share of the text width the image takes, or 0 for an
+// unknown size.
+func (img Image) fraction() float64 {
+	switch img.Size {
+	case "", ImageFull:
+		return 1
+	case ImageHalf:
+		return 1.0 / 2
+	case ImageThird:
+		return 1.0 / 3
+	}
+	return 0
+}
+
+// figure is a timeline image that can be shown, normalized and numbered.
+type figure struct {
+	Image
+	normalizedImage
+	Number int // from 1, across the whole report
+}
+
+// label is "Figure N: caption", or just "Figure N" without a caption.
+func (f figure) label(lang string) string {
+	label := fmt.Sprintf("%s %d", tr(lang, "Figure"), f.Number)
+	if f.Caption != "" {
+		label += ": " + f.Caption
+	}
+	return label
+}
+
+// alt is the text alternative of the image: Alt, the caption, or the
+// figure number.
+func (f figure) alt(lang string) string {
+	if f.Alt != "" {
+		return f.Alt
+	}
+	if f.Caption != "" {
+		return f.Caption
+	}
+	return fmt.Sprintf("%s %d", tr(lang, "Figure"), f.Number)
+}
+
+// timelineFigures normalizes the images of each timeline entry for their
+// printed width and numbers them in reading order. Images that cannot be
+// decoded are left out and do not take a number; an unknown size is shown
+// full width.
+func timelineFigures(data PostmortemData) [][]figure {
+	margins := data.Options.Theme.theme().Margins
+	textW := data.Options.Page.paper().W - margins.Left - margins.Right
+	figures := make([][]figure, len(data.Timeline))
+	n := 0
+	for i, entry := range data.Timeline {
+		for _, img := range entry.Images {
+			if img.fraction() == 0 {
+				img.Size = ImageFull
+			}
+			normalized, err := loadImage(img.Src, figureWidth(img.fraction(), textW), data.Options.Images)
+			if err != nil {
+				continue
+			}
+			n++
+			figures[i] = append(figures[i], figure{img, normalized, n})
+		}
+	}
+	return figures
+}
+
+// figureRows lays figures out in rows, in order, starting a new row when
+// the next figure does not fit in what is left of the text width.
+func figureRows(figures []figure) [][]figure {
+	var rows [][]figure
+	used := 0.0
+	for _, f := range figures {
+		frac := f.fraction()
+		if len(rows) == 0 || used+frac > 1.001 {
+			rows = append(rows, nil)
+			used = 0
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], f)
+		used += frac
+	}
+	return rows
+}
+
+// figureWidth is the width of a figure of the given fraction in a text
+// width of textW, leaving figureGap between the figures of a row.
+func figureWidth(frac, textW float64) float64 {
+	return frac*(textW+figureGap) - figureGap
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageJSON(t *testing.T) {
+	var entry TimelineEntry
+	require.NoError(t, json.Unmarshal([]byte(`{"images": [
+		"data:image/png;base64,AAAA",
+		{"src": "data:image/png;base64,BBBB", "caption": "Error rate", "alt": "Graph", "size": "half"}
+	]}`), &entry))
+	assert.Equal(t, []Image{
+		{Src: "data:image/png;base64,AAAA"},
+		{Src: "data:image/png;base64,BBBB", Caption: "Error rate", Alt: "Graph", Size: ImageHalf},
+	}, entry.Images)
+}
+
+func figureData() PostmortemData {
+	return PostmortemData{
+		Title: "Checkout API Failure",
+		Lang:  "en",
+		Timeline: []TimelineEntry{
+			{ID: "t1", Time: "02:22", Images: []Image{{Src: tinyPNG, Caption: "Error rate"}, {Src: "data:image/bmp;base64,Qk0="}}},
+			{ID: "t2", Time: "02:30", Images: []Image{
+				{Src: tinyPNG, Size: ImageHalf, Alt: "Pods"},
+				{Src: tinyPNG, Size: ImageThird},
+				{Src: tinyPNG, Size: ImageThird},
+				{Src: tinyPNG, Size: "huge"},
+			}},
+		},
+	}
+}
+
+func TestTimelineFigures(t *testing.T) {
+	figures := timelineFigures(figureData())
+	require.Len(t, figures, 2)
+	require.Len(t, figures[0], 1, "images that cannot be decoded are left out")
+	require.Len(t, figures[1], 4)
+	assert.Equal(t, 1, figures[0][0].Number)
+	assert.Equal(t, 2, figures[1][0].Number, "numbers run across entries")
+	assert.Equal(t, ImageFull, figures[1][3].Size, "unknown sizes are shown full width")
+
+	assert.Equal(t, "Figure 1: Error rate", figures[0][0].label("en"))
+	assert.Equal(t, "Error rate", figures[0][0].alt("en"))
+	assert.Equal(t, "Pods", figures[1][0].alt("en"))
+	assert.Equal(t, "Figure 3", figures[1][1].alt("en"))
+
+	var sizes [][]string
+	for _, row := range figureRows(figures[1]) {
+		var r []string
+		for _, f := range row {
+			r = append(r, f.Size)
+		}
+		sizes = append(sizes, r)
+	}
+	assert.Equal(t, [][]string{{ImageHalf, ImageThird}, {ImageThird}, {ImageFull}}, sizes)
+
+	assert.InDelta(t, 180.0, figureWidth(1, 180), 1e-9)
+	assert.InDelta(t, 88.0, figureWidth(0.5, 180), 1e-9)
+	assert.InDelta(t, 2*figureWidth(1.0/3, 180)+figureGap, figureWidth(2.0/3, 180), 1e-9)
+}
+
+func TestFigureRenderers(t *testing.T) {
+	data := figureData()
+
+	t.Run("pdf", func(t *testing.T) {
+		figures := timelineFigures(data)
+		drawPDF(t, func(w *pdfWriter) {
+			w.pdf.SetY(200)
+			w.figures(figures[0], "en")
+			assert.Equal(t, 2, w.pdf.PageNo(), "a full width figure and its caption move to the next page whole")
+
+			w.figures(figures[1], "en")
+			assert.Equal(t, 4, w.pdf.PageNo(), "the half and third rows share a page, the full one takes the next")
+		})
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+		out := buf.String()
+		assert.Contains(t, out, `<figure class="full"><img src="data:image/png;base64,`)
+		assert.Contains(t, out, `alt="Error rate"><figcaption>Figure 1: Error rate</figcaption></figure>`)
+		assert.Contains(t, out, `<figure class="half"><img src="data:image/png;base64,`)
+		assert.Contains(t, out, `<figcaption>Figure 5</figcaption>`)
+	})
+
+	t.Run("docx", func(t *testing.T) {
+		d := &docxWriter{lang: "en", theme: DefaultTheme()}
+		d.figures(timelineFigures(data)[1])
+		body := d.body.String()
+		assert.Len(t, d.media, 4)
+		assert.Contains(t, body, "<w:cantSplit/>", "grid rows are not split across pages")
+		assert.Contains(t, body, "<w:keepNext/>", "images stay with their captions")
+		assert.Contains(t, body, "Figure 2")
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		doc, images := renderMarkdown(data, false)
+		assert.Contains(t, doc, "![Error rate](images/figure-1.png)\n\n*Figure 1: Error rate*")
+		assert.Contains(t, doc, "![Pods](images/figure-2.png)")
+		assert.Len(t, images, 5)
+	})
+}
@@ -0,0 +1,251 @@
+package report
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// DefaultFontFamily is the typeface embedded in the binary. It is always
+// available and comes right after the theme's own fonts in the fallback chain.
+const DefaultFontFamily = "DejaVu Sans"
+
+// DefaultMonoFontFamily is the embedded typeface for inline code and code
+// blocks.
+const DefaultMonoFontFamily = "DejaVu Sans Mono"
+
+//go:embed fonts/DejaVuSans.ttf fonts/DejaVuSans-Bold.ttf fonts/DejaVuSansMono.ttf
+var embeddedFonts embed.FS
+
+// fontFace is one TrueType file. Glyph lookups are cached because the
+// fallback chain asks about every character of the report.
+type fontFace struct {
+	data []byte
+	font *sfnt.Font
+
+	mu     sync.Mutex
+	glyphs map[rune]bool
+}
+
+func parseFontFace(data []byte) (*fontFace, error) {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return &fontFace{data: data, font: f, glyphs: map[rune]bool{}}, nil
+}
+
+// has reports whether the face has a glyph for r.
+func (f *fontFace) has(r rune) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ok, cached := f.glyphs[r]
+	if !cached {
+		var buf sfnt.Buffer
+		i, err := f.font.GlyphIndex(&buf, r)
+		ok = err == nil && i != 0
+		f.glyphs[r] = ok
+	}
+	return ok
+}
+
+// fontFamily is a typeface with up to four faces. Any of them may be
+// missing, but a family always has at least one.
+type fontFamily struct {
+	name                              string
+	regular, bold, italic, boldItalic *fontFace
+}
+
+// face returns the face for a gofpdf style ("", "B", "I" or "BI"). Missing
+// faces are stood in for by the closest one the family has: italic text is
+// set upright rather than in another typeface.
+func (f *fontFamily) face(style string) *fontFace {
+	var order []*fontFace
+	switch style {
+	case "B":
+		order = []*fontFace{f.bold, f.regular, f.boldItalic, f.italic}
+	case "I":
+		order = []*fontFace{f.italic, f.regular, f.boldItalic, f.bold}
+	case "BI":
+		order = []*fontFace{f.boldItalic, f.bold, f.italic, f.regular}
+	default:
+		order = []*fontFace{f.regular, f.bold, f.italic, f.boldItalic}
+	}
+	for _, face := range order {
+		if face != nil {
+			return face
+		}
+	}
+	return nil
+}
+
+// FontLibrary holds the typefaces PDFs can be set in: the embedded DejaVu
+// Sans and DejaVu Sans Mono plus the TrueType families found in the font
+// directories. It is safe for concurrent use.
+type FontLibrary struct {
+	families []*fontFamily          // in discovery order, the embedded families last unless a directory has them
+	byName   map[string]*fontFamily // keyed by lower-case family name
+}
+
+var (
+	defaultFonts     *FontLibrary
+	defaultFontsOnce sync.Once
+)
+
+// DefaultFonts is the library with just the embedded fonts.
+func DefaultFonts() *FontLibrary {
+	defaultFontsOnce.Do(func() {
+		lib, err := LoadFonts()
+		if err != nil {
+			panic(err) // the embedded fonts are part of the binary
+		}
+		defaultFonts = lib
+	})
+	return defaultFonts
+}
+
+// LoadFonts builds a library from the .ttf files under dirs, searched in
+// order; the first file found for a family and style wins. Directories that
+// do not exist are skipped. Only regular, bold and italic faces are used,
+// and OpenType/CFF (.otf) files are not supported by the PDF writer.
+func LoadFonts(dirs ...string) (*FontLibrary, error) {
+	lib := &FontLibrary{byName: map[string]*fontFamily{}}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if err := lib.addDir(os.DirFS(dir), dir); err != nil {
+			return nil, err
+		}
+	}
+	if err := lib.addDir(embeddedFonts, "embedded"); err != nil {
+		return nil, err
+	}
+	return lib, nil
+}
+
+func (l *FontLibrary) addDir(fsys fs.FS, label string) error {
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".ttf") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		face, err := parseFontFace(data)
+		if err != nil {
+			return fmt.Errorf("font %s: %w", filepath.Join(label, path), err)
+		}
+		l.add(face)
+	}
+	return nil
+}
+
+// add files face under its family, as a regular, bold, italic or bold
+// italic face. Other weights and widths are ignored.
+func (l *FontLibrary) add(face *fontFace) {
+	var buf sfnt.Buffer
+	name := func(ids ...sfnt.NameID) string {
+		for _, id := range ids {
+			if s, err := face.font.Name(&buf, id); err == nil && s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	family := name(sfnt.NameIDTypographicFamily, sfnt.NameIDFamily)
+	if family == "" {
+		return
+	}
+	var bold, italic bool
+	switch strings.ToLower(name(sfnt.NameIDTypographicSubfamily, sfnt.NameIDSubfamily)) {
+	case "", "regular", "book", "normal", "roman":
+	case "bold":
+		bold = true
+	case "italic", "oblique":
+		italic = true
+	case "bold italic", "bold oblique":
+		bold, italic = true, true
+	default:
+		return
+	}
+
+	key := strings.ToLower(family)
+	f := l.byName[key]
+	if f == nil {
+		f = &fontFamily{name: family}
+		l.byName[key] = f
+		l.families = append(l.families, f)
+	}
+	slot := &f.regular
+	switch {
+	case bold && italic:
+		slot = &f.boldItalic
+	case bold:
+		slot = &f.bold
+	case italic:
+		slot = &f.italic
+	}
+	if *slot == nil {
+		*slot = face
+	}
+}
+
+// Families lists the family names in the library.
+func (l *FontLibrary) Families() []string {
+	names := make([]string, len(l.families))
+	for i, f := range l.families {
+		names[i] = f.name
+	}
+	return names
+}
+
+// Has reports whether the library has the named family.
+func (l *FontLibrary) Has(family string) bool {
+	return l.byName[strings.ToLower(family)] != nil
+}
+
+// chain is the order in which families are tried for each character: the
+// theme's family and fallbacks, then DejaVu Sans, then every other family in
+// the library. Unknown names are skipped, so the chain is never empty.
+func (l *FontLibrary) chain(t ThemeFonts) []*fontFamily {
+	var out []*fontFamily
+	seen := map[*fontFamily]bool{}
+	add := func(f *fontFamily) {
+		if f != nil && !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	for _, name := range append(append([]string{t.Family}, t.Fallback...), DefaultFontFamily) {
+		add(l.byName[strings.ToLower(name)])
+	}
+	for _, f := range l.families {
+		add(f)
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func goFontDir(t *testing.T) string {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"Go-Regular.ttf": goregular.TTF,
+		"Go-Bold.ttf":    gobold.TTF,
+		"Go-Italic.ttf":  goitalic.TTF,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o644))
+	}
+	return dir
+}
+
+func TestLoadFonts(t *testing.T) {
+	lib, err := LoadFonts(goFontDir(t), filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go", DefaultFontFamily, DefaultMonoFontFamily}, lib.Families())
+	assert.True(t, lib.Has("go"))
+
+	goFamily := lib.byName["go"]
+	require.NotNil(t, goFamily.regular)
+	require.NotNil(t, goFamily.bold)
+	require.NotNil(t, goFamily.italic)
+	assert.Same(t, goFamily.italic, goFamily.face("I"))
+	assert.Same(t, goFamily.bold, goFamily.face("BI"), "no bold italic face: bold stands in")
+
+	dejaVu := lib.byName["dejavu sans"]
+	assert.Same(t, dejaVu.regular, dejaVu.face("I"), "no italic face: set upright")
+
+	chain := lib.chain(ThemeFonts{Family: "Unknown", Fallback: []string{"Go"}})
+	assert.Equal(t, []*fontFamily{goFamily, dejaVu, lib.byName["dejavu sans mono"]}, chain)
+	assert.Equal(t, []string{DefaultFontFamily, DefaultMonoFontFamily}, DefaultFonts().Families())
+}
+
+func TestFontFallbackRuns(t *testing.T) {
+	lib, err := LoadFonts(goFontDir(t))
+	require.NoError(t, err)
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	w := newPDFWriter(pdf, DefaultTheme(), "en", lib)
+	w.font("", 10)
+
+	// Georgian is in DejaVu Sans but not in the Go fonts.
+	require.False(t, lib.byName["go"].regular.has('ქ'))
+	require.True(t, lib.byName["dejavu sans"].regular.has('ქ'))
+
+	w.fonts = lib.chain(ThemeFonts{Family: "Go"})
+	assert.Equal(t, []textRun{{0, "Deploy "}, {1, "ქართ "}, {0, "ok"}}, w.runs("Deploy ქართ ok"))
+	assert.Equal(t, []textRun{{0, "plain text"}}, w.runs("plain text"))
+}
+
+func TestPDFRendererFontFallback(t *testing.T) {
+	lib, err := LoadFonts(goFontDir(t))
+	require.NoError(t, err)
+	data := PostmortemData{
+		Title:   "Отказ API — ქართ",
+		Summary: "Mixed scripts: Ελληνικά, ქართული, 日本語",
+		Options: Options{Theme: ThemeRef{Theme: &Theme{Fonts: ThemeFonts{Family: "Go"}}}},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, PDFRenderer{Fonts: lib}.Render(context.Background(), data, &buf))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
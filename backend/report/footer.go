@@ -0,0 +1,26 @@
+package report
+
+import (
+	"strings"
+	"time"
+)
+
+// pageNumberAlias is replaced by the total page count when the PDF is written.
+const pageNumberAlias = "{nb}"
+
+// pageLabel returns the localized "Page 3 of {nb}" text, with total standing
+// in for the page count.
+func pageLabel(page, total, lang string) string {
+	return tr(lang, "Page") + " " + page + " " + tr(lang, "of") + " " + total
+}
+
+// footerText splits the configured footer into what goes left of the page
+// number (classification and incident ID) and right of it (the generation time).
+func footerText(data PostmortemData, now time.Time) (left, right string) {
+	f := data.Options.Footer
+	left = strings.Join(nonEmpty(strings.TrimSpace(f.Classification), strings.TrimSpace(f.IncidentID)), " · ")
+	if f.Timestamp {
+		right = tr(data.Lang, "Generated") + " " + formatDateTime(now.In(incidentLocation(data)), data.Lang, true)
+	}
+	return left, right
+}
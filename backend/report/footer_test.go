@@ -0,0 +1,25 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFooterText(t *testing.T) {
+	now := time.Date(2025, 10, 18, 17, 30, 0, 0, time.UTC)
+	data := PostmortemData{
+		Lang:     "pt",
+		Timezone: "America/Sao_Paulo",
+		Options:  Options{Footer: FooterOptions{Classification: "Confidencial", IncidentID: "INC-42", Timestamp: true}},
+	}
+	left, right := footerText(data, now)
+	assert.Equal(t, "Confidencial · INC-42", left)
+	assert.Equal(t, "Gerado em 18/10/2025 14:30 -03", right)
+	assert.Equal(t, "Página 3 de {nb}", pageLabel("3", pageNumberAlias, "pt"))
+
+	left, right = footerText(PostmortemData{}, now)
+	assert.Empty(t, left)
+	assert.Empty(t, right)
+}
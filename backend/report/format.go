@@ -0,0 +1,56 @@
+package report
+
+import (
+	"strings"
+	"time"
+)
+
+func tr(lang, key string) string {
+	if translations[lang] != nil && translations[lang][key] != "" {
+		return translations[lang][key]
+	}
+	return key // fallback
+}
+
+func formatSeverity(sev, lang string) string {
+	sev = strings.ToUpper(strings.TrimSpace(sev))
+	if lang == "pt" {
+		switch sev {
+		case "SEV-1":
+			return "SEV-1 (Crítico)"
+		case "SEV-2":
+			return "SEV-2 (Alto)"
+		case "SEV-3":
+			return "SEV-3 (Moderado)"
+		case "SEV-4":
+			return "SEV-4 (Baixo)"
+		default:
+			return sev
+		}
+	} else {
+		switch sev {
+		case "SEV-1":
+			return "SEV-1 (Critical)"
+		case "SEV-2":
+			return "SEV-2 (High)"
+		case "SEV-3":
+			return "SEV-3 (Moderate)"
+		case "SEV-4":
+			return "SEV-4 (Low)"
+		default:
+			return sev
+		}
+	}
+}
+
+// Formata data conforme idioma
+func formatDate(dateStr, lang string) string {
+	parsed, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return dateStr
+	}
+	if lang == "pt" {
+		return parsed.Format("02/01/2006")
+	}
+	return parsed.Format("2006-01-02")
+}
@@ -0,0 +1,541 @@
+package report
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//go:embed templates/report.html
+var reportHTML string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"tr":  tr,
+	"inc": func(i int) int { return i + 1 },
+}).Parse(reportHTML))
+
+type htmlSection struct {
+	Title    string
+	Content  template.HTML // from richHTML
+	Snippets []htmlSnippet
+}
+
+// htmlSnippet is the view of a Snippet for the "snippet" template.
+type htmlSnippet struct {
+	Title       string
+	LineNumbers bool
+	Lines       []snippetLine
+}
+
+func newHTMLSnippet(s Snippet) htmlSnippet {
+	return htmlSnippet{Title: s.Title, LineNumbers: s.LineNumbers, Lines: s.lines()}
+}
+
+func htmlSnippets(snippets []Snippet) []htmlSnippet {
+	var out []htmlSnippet
+	for _, s := range snippets {
+		out = append(out, newHTMLSnippet(s))
+	}
+	return out
+}
+
+type htmlLessons struct {
+	Good, Improve template.HTML // from richHTML
+}
+
+type htmlAction struct {
+	Action
+	Badges actionBadges
+}
+
+type htmlReference struct {
+	Number int
+	Label  string
+	URL    template.URL // only http(s) and mailto, checked by parseReferences
+}
+
+type htmlTimelineEntry struct {
+	Time     string
+	Actor    string
+	Notes    template.HTML // from richHTML, led by "Notes:"
+	Snippets []htmlSnippet
+	Figures  []htmlFigure
+}
+
+// htmlFigure is a timeline image in the figure grid; Size is its column
+// class ("full", "half" or "third").
+type htmlFigure struct {
+	URL     template.URL
+	Alt     string
+	Caption string // "Figure N: caption"
+	Size    string
+}
+
+// htmlReport is the view model for templates/report.html. Values are already
+// formatted for the report language so the template only lays them out.
+type htmlReport struct {
+	Lang         string
+	ThemeCSS     template.CSS
+	PageCSS      template.CSS
+	Title        string
+	Date         string
+	Severity     string
+	Creator      string
+	Duration     string
+	Start        string
+	StartUTC     string
+	End          string
+	EndUTC       string
+	Timezone     string
+	Metrics      []incidentMetric
+	Owners       string
+	Affected     string
+	Status       string
+	Watermark    string
+	Logo         template.URL
+	Header       template.URL
+	Footer       template.URL
+	Summary      []htmlSection
+	Sections     []htmlSection
+	Timeline     []htmlTimelineEntry
+	Chart        *htmlChart
+	MetricCharts []htmlMetricChart
+	Actions      []htmlAction
+	CAPATable    bool
+	Lessons      htmlLessons
+	References   []htmlReference
+}
+
+// HTMLRenderer writes postmortems as a single self-contained HTML page with
+// inline CSS and images embedded as data URLs.
+type HTMLRenderer struct{}
+
+// MediaType implements Renderer.
+func (HTMLRenderer) MediaType() string { return "text/html; charset=utf-8" }
+
+// Extension implements Renderer.
+func (HTMLRenderer) Extension() string { return ".html" }
+
+// Render implements Renderer.
+func (HTMLRenderer) Render(ctx context.Context, data PostmortemData, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	lang := data.Lang
+	if lang == "" {
+		lang = "en"
+	}
+	times := formatIncidentTimes(data)
+	paper := data.Options.Page.paper()
+	r := htmlReport{
+		Lang:     lang,
+		ThemeCSS: themeCSS(data.Options.Theme.theme()),
+		PageCSS:  pageCSS(data.Options.Page),
+		Title:    data.Title,
+		Date:     times.Date,
+		Severity: formatSeverity(data.Severity, data.Lang),
+		Creator:  data.Creator,
+		Duration: times.Duration,
+		Start:    times.Start,
+		StartUTC: times.StartUTC,
+		End:      times.End,
+		EndUTC:   times.EndUTC,
+		Timezone: data.Timezone,
+		Metrics:  incidentMetrics(data),
+		Owners:   data.Owners,
+		Affected: data.Affected,
+		Lessons:  htmlLessons{Good: richHTML("", data.Lessons.Good), Improve: richHTML("", data.Lessons.Improve)},
+		Logo:     imageURL(data.Branding.Logo, paper.W*0.35, data.Options.Images),
+		Header:   imageURL(data.Branding.Header, paper.W, data.Options.Images),
+		Footer:   imageURL(data.Branding.Footer, paper.W, data.Options.Images),
+	}
+	r.Status = statusLabel(data.status(), data.Lang)
+	if needsWatermark(data.status()) {
+		r.Watermark = strings.ToUpper(r.Status)
+	}
+
+	r.Summary = nonEmptySections(
+		narrative{tr(data.Lang, "Executive Summary"), data.Summary, data.Snippets.Summary},
+		narrative{tr(data.Lang, "Customer Impact"), data.Impact, data.Snippets.Impact},
+	)
+	r.Sections = nonEmptySections(
+		narrative{tr(data.Lang, "Root Cause"), data.RootCause, data.Snippets.RootCause},
+		narrative{tr(data.Lang, "Detection"), data.Detection, data.Snippets.Detection},
+		narrative{tr(data.Lang, "Incident Response"), data.Response, data.Snippets.Response},
+		narrative{tr(data.Lang, "Communications"), data.Comm, data.Snippets.Comm},
+	)
+
+	if chart, ok := buildTimelineChart(data); ok {
+		r.Chart = newHTMLChart(chart, data.Options)
+	}
+	for _, chart := range metricCharts(data) {
+		r.MetricCharts = append(r.MetricCharts, newHTMLMetricChart(chart, data.Options))
+	}
+	figures := timelineFigures(data)
+	for i, entry := range data.Timeline {
+		e := htmlTimelineEntry{
+			Time:     entry.Time,
+			Actor:    entry.Actor,
+			Notes:    richHTML(tr(data.Lang, "Notes:"), entry.Notes),
+			Snippets: htmlSnippets(entry.Snippets),
+		}
+		for _, f := range figures[i] {
+			e.Figures = append(e.Figures, htmlFigure{URL: dataURL(f.normalizedImage), Alt: f.alt(data.Lang), Caption: f.label(data.Lang), Size: f.Size})
+		}
+		r.Timeline = append(r.Timeline, e)
+	}
+
+	now := time.Now()
+	r.CAPATable = data.Options.CAPALayout == CAPALayoutTable
+	for _, a := range data.Actions {
+		badges := badgesFor(a, data.Lang, now)
+		a.Due = formatDate(a.Due, data.Lang)
+		r.Actions = append(r.Actions, htmlAction{Action: a, Badges: badges})
+	}
+
+	for _, ref := range parseReferences(data.References) {
+		r.References = append(r.References, htmlReference{Number: ref.Number, Label: ref.Label, URL: template.URL(ref.URL)})
+	}
+
+	return htmlTemplate.Execute(w, r)
+}
+
+// fontFamilyUnsafe matches what may not appear in a CSS font family name.
+var fontFamilyUnsafe = regexp.MustCompile(`[^\p{L}\p{N} _-]`)
+
+// themeCSS declares the theme as CSS custom properties, which the stylesheet
+// in templates/report.html refers to. withDefaults has already replaced any
+// color that is not "#RRGGBB", so the values are safe to inline.
+func themeCSS(t Theme) template.CSS {
+	p, z, m := t.Palette, t.Sizes, t.Margins
+	var families []string
+	for _, name := range append([]string{t.Fonts.Family}, t.Fonts.Fallback...) {
+		if name = strings.TrimSpace(fontFamilyUnsafe.ReplaceAllString(name, "")); name != "" {
+			families = append(families, `"`+name+`"`)
+		}
+	}
+	if len(families) == 0 {
+		families = []string{`"` + DefaultFontFamily + `"`}
+	}
+	mono := DefaultMonoFontFamily
+	if name := strings.TrimSpace(fontFamilyUnsafe.ReplaceAllString(t.Fonts.Mono, "")); name != "" {
+		mono = name
+	}
+	return template.CSS(fmt.Sprintf(":root { "+
+		"--primary: %s; --accent: %s; --text: %s; --muted: %s; --rule: %s; --band: %s; "+
+		"--font: %s, Verdana, sans-serif; --mono: \"%s\", monospace; --code-shade: %s; "+
+		"--diff-add-bg: %s; --diff-add: %s; --diff-del-bg: %s; --diff-del: %s; "+
+		"--title: %gpt; --heading: %gpt; --section: %gpt; --subsection: %gpt; --body: %gpt; --small: %gpt; "+
+		"--margin-top: %gmm; --margin-right: %gmm; --margin-bottom: %gmm; --margin-left: %gmm; "+
+		"--line-height: %g; }",
+		p.Primary, p.Accent, p.Text, p.Muted, p.Rule, p.Band,
+		strings.Join(families, ", "), mono, codeShade,
+		snippetLineColors[lineAdded][0], snippetLineColors[lineAdded][1], snippetLineColors[lineRemoved][0], snippetLineColors[lineRemoved][1],
+		z.Title, z.Heading, z.Section, z.Subsection, z.Body, z.Small,
+		m.Top, m.Right, m.Bottom, m.Left,
+		1.6*t.LineSpacing))
+}
+
+// pageCSS sets the paper used when the page is printed, and the width of
+// the page on screen.
+func pageCSS(p PageOptions) template.CSS {
+	paper := p.paper()
+	orientation := OrientationPortrait
+	if p.landscape() {
+		orientation = OrientationLandscape
+	}
+	return template.CSS(fmt.Sprintf(":root { --page-width: %gmm; } @page { size: %s %s; }", paper.W, paper.Name, orientation))
+}
+
+// htmlChart is a timelineChart laid out as an SVG in millimeters, with the
+// text width of the page as its width, so it matches the PDF drawing.
+type htmlChart struct {
+	Width, Height, Axis float64
+	TickEnd, TickLabel  float64 // where tick marks end and their labels sit
+	Marker              float64
+	FontSize            float64 // the theme's small size, in millimeters
+	Bands               []htmlChartBand
+	Ticks               []htmlChartTick
+	Events              []htmlChartEvent
+	Legend              []chartLegend
+}
+
+type htmlChartBand struct {
+	X, Y, W, H float64
+	Label      string
+	Fill       Color
+}
+
+type htmlChartTick struct {
+	X     float64
+	Label string
+}
+
+type htmlChartEvent struct {
+	X, StemTop, LabelX float64
+	Anchor             string // text-anchor of the label
+	Label              string
+	Color              Color
+}
+
+func newHTMLChart(c timelineChart, opts Options) *htmlChart {
+	th := opts.Theme.theme()
+	width := opts.Page.paper().W - th.Margins.Left - th.Margins.Right
+	mm := func(v float64) float64 { return math.Round(v*100) / 100 }
+	x := func(at float64) float64 { return mm(at * width) }
+
+	h := &htmlChart{
+		Width:     mm(width),
+		Height:    chartHeight,
+		Axis:      chartAxis,
+		TickEnd:   chartAxis + 1.5,
+		TickLabel: chartAxis + 5,
+		Marker:    chartMarker,
+		FontSize:  mm(th.Sizes.Small * 25.4 / 72),
+		Legend:    c.Legend,
+	}
+	for _, b := range c.Bands {
+		h.Bands = append(h.Bands, htmlChartBand{x(b.From), chartBandTop, mm(x(b.To) - x(b.From)), chartAxis - chartBandTop, b.Label, b.Fill})
+	}
+	for _, t := range c.Ticks {
+		h.Ticks = append(h.Ticks, htmlChartTick{x(t.At), t.Label})
+	}
+	for _, e := range c.Events {
+		ev := htmlChartEvent{X: x(e.At), StemTop: chartAxis - chartStem - float64(e.Level)*chartStagger, Label: e.Label, Color: e.Color}
+		ev.LabelX, ev.Anchor = mm(ev.X+0.8), "start"
+		if e.At > chartFlipLeft {
+			ev.LabelX, ev.Anchor = mm(ev.X-0.8), "end"
+		}
+		h.Events = append(h.Events, ev)
+	}
+	return h
+}
+
+// htmlMetricChart is a metricChart laid out as an SVG in millimeters, like
+// htmlChart. The title and the legend are HTML around it.
+type htmlMetricChart struct {
+	Title                      string
+	Width, Height, FontSize    float64
+	PlotX, PlotY, PlotW, PlotH float64
+	PlotBottom                 float64
+	TickEnd, TickLabel         float64
+	LineWidth                  float64
+	Bands                      []htmlChartBand
+	YTicks                     []htmlValueTick
+	XTicks                     []htmlChartTick
+	Marks                      []htmlChartMark
+	Lines                      []htmlChartLine
+	Legend                     []chartLegend
+}
+
+type htmlValueTick struct {
+	Y     float64
+	Label string
+}
+
+type htmlChartMark struct {
+	X, LabelX, LabelY float64
+	Anchor            string
+	Label             string
+}
+
+type htmlChartLine struct {
+	Color  Color
+	Points string // as in the points attribute of a polyline
+}
+
+func newHTMLMetricChart(c metricChart, opts Options) htmlMetricChart {
+	th := opts.Theme.theme()
+	width := opts.Page.paper().W - th.Margins.Left - th.Margins.Right
+	mm := func(v float64) float64 { return math.Round(v*100) / 100 }
+	fontSize := th.Sizes.Small * 25.4 / 72
+
+	// SVG text cannot be measured here, so the gutter assumes digits about
+	// 0.6em wide.
+	var chars int
+	for _, t := range c.YTicks {
+		chars = max(chars, utf8.RuneCountInString(t.Label))
+	}
+	gutter := float64(chars)*0.6*fontSize + 2
+
+	const top = 1.0 // room for the topmost tick label
+	h := htmlMetricChart{
+		Title:      c.Title,
+		Width:      mm(width),
+		Height:     top + metricPlot + metricXLabel,
+		FontSize:   mm(fontSize),
+		PlotX:      mm(gutter),
+		PlotY:      top,
+		PlotW:      mm(width - gutter),
+		PlotH:      metricPlot,
+		PlotBottom: top + metricPlot,
+		TickEnd:    top + metricPlot + 1.5,
+		TickLabel:  top + metricPlot + 5,
+		LineWidth:  metricLine,
+		Legend:     c.Legend,
+	}
+	x := func(at float64) float64 { return mm(gutter + at*(width-gutter)) }
+	y := func(at float64) float64 { return mm(top + (1-at)*metricPlot) }
+	for _, b := range c.Bands {
+		h.Bands = append(h.Bands, htmlChartBand{x(b.From), top, mm(x(b.To) - x(b.From)), metricPlot, b.Label, b.Fill})
+	}
+	for _, t := range c.YTicks {
+		h.YTicks = append(h.YTicks, htmlValueTick{y(t.At), t.Label})
+	}
+	for _, t := range c.XTicks {
+		h.XTicks = append(h.XTicks, htmlChartTick{x(t.At), t.Label})
+	}
+	for _, m := range c.Marks {
+		mark := htmlChartMark{X: x(m.At), LabelY: mm(top + 0.5 + float64(m.Level)*metricMark + metricMark/2), Label: m.Label}
+		mark.LabelX, mark.Anchor = mm(mark.X+0.8), "start"
+		if m.At > chartFlipLeft {
+			mark.LabelX, mark.Anchor = mm(mark.X-0.8), "end"
+		}
+		h.Marks = append(h.Marks, mark)
+	}
+	for _, l := range c.Lines {
+		points := make([]string, len(l.Points))
+		for i, p := range l.Points {
+			points[i] = fmt.Sprintf("%g,%g", x(p.X), y(p.Y))
+		}
+		h.Lines = append(h.Lines, htmlChartLine{l.Color, strings.Join(points, " ")})
+	}
+	return h
+}
+
+// narrative is a titled narrative field and its snippets.
+type narrative struct {
+	title, text string
+	snippets    []Snippet
+}
+
+// nonEmptySections turns the narrative fields that have content into
+// sections.
+func nonEmptySections(fields ...narrative) []htmlSection {
+	var out []htmlSection
+	for _, f := range fields {
+		if hasContent(f.text, f.snippets) {
+			out = append(out, htmlSection{f.title, richHTML("", f.text), htmlSnippets(f.snippets)})
+		}
+	}
+	return out
+}
+
+// richHTML renders a narrative field's Markdown subset as HTML. All text is
+// escaped and only links accepted by referenceURL are kept, so the result
+// is safe to inline. Headings start at h3, below the section's h2. lead,
+// when set, starts the first paragraph.
+func richHTML(lead, text string) template.HTML {
+	blocks := withLead(lead, parseRichText(text))
+	if len(blocks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	type openList struct {
+		tag   string
+		level int
+	}
+	var lists []openList
+	closeLists := func(keep func(openList) bool) {
+		for len(lists) > 0 && !keep(lists[len(lists)-1]) {
+			fmt.Fprintf(&b, "</li></%s>", lists[len(lists)-1].tag)
+			lists = lists[:len(lists)-1]
+		}
+	}
+
+	for _, block := range blocks {
+		if !isListItem(block) {
+			closeLists(func(openList) bool { return false })
+		}
+		switch block.Kind {
+		case blockHeading:
+			level := min(block.Level+2, 6)
+			fmt.Fprintf(&b, "<h%d>", level)
+			htmlSpans(&b, block.Spans)
+			fmt.Fprintf(&b, "</h%d>", level)
+		case blockBullet, blockNumbered:
+			tag := "ul"
+			if block.Kind == blockNumbered {
+				tag = "ol"
+			}
+			closeLists(func(l openList) bool {
+				return l.level < block.Level || (l.level == block.Level && l.tag == tag)
+			})
+			if n := len(lists); n > 0 && lists[n-1].level == block.Level {
+				b.WriteString("</li>")
+			} else {
+				if tag == "ol" && block.Number != 1 {
+					fmt.Fprintf(&b, `<ol start="%d">`, block.Number)
+				} else {
+					fmt.Fprintf(&b, "<%s>", tag)
+				}
+				lists = append(lists, openList{tag, block.Level})
+			}
+			b.WriteString("<li>")
+			htmlSpans(&b, block.Spans)
+		case blockCode:
+			// The template escapes the code; it cannot fail on this view.
+			_ = htmlTemplate.ExecuteTemplate(&b, "snippet", newHTMLSnippet(Snippet{Lang: block.Lang, Code: block.Code}))
+		default:
+			b.WriteString("<p>")
+			htmlSpans(&b, block.Spans)
+			b.WriteString("</p>")
+		}
+	}
+	closeLists(func(openList) bool { return false })
+	return template.HTML(b.String())
+}
+
+// htmlSpans writes inline text, escaped, with its formatting.
+func htmlSpans(b *strings.Builder, spans []richSpan) {
+	for _, s := range spans {
+		var open, close []string
+		wrap := func(o, c string) {
+			open = append(open, o)
+			close = append([]string{c}, close...)
+		}
+		if s.URL != "" {
+			wrap(`<a href="`+template.HTMLEscapeString(s.URL)+`" rel="noopener noreferrer">`, "</a>")
+		}
+		if s.Bold {
+			wrap("<strong>", "</strong>")
+		}
+		if s.Italic {
+			wrap("<em>", "</em>")
+		}
+		if s.Code {
+			wrap("<code>", "</code>")
+		}
+		b.WriteString(strings.Join(open, ""))
+		b.WriteString(strings.ReplaceAll(template.HTMLEscapeString(s.Text), "\n", "<br>"))
+		b.WriteString(strings.Join(close, ""))
+	}
+}
+
+// imageURL normalizes an image data URL for printing at widthMM
+// millimeters and encodes it again, so it can be trusted in a src
+// attribute. Anything that is not a decodable image is dropped.
+func imageURL(url string, widthMM float64, opts ImageOptions) template.URL {
+	if url == "" {
+		return ""
+	}
+	img, err := loadImage(url, widthMM, opts)
+	if err != nil {
+		return ""
+	}
+	return dataURL(img)
+}
+
+// dataURL is img as a data URL.
+func dataURL(img normalizedImage) template.URL {
+	return template.URL("data:" + img.MimeType + ";base64," + base64.StdEncoding.EncodeToString(img.Data))
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePostmortemHTML(t *testing.T) {
+	data := PostmortemData{
+		Title:      "Falha <script>",
+		Severity:   "SEV-1",
+		Summary:    "Checkout degradado.",
+		Timeline:   []TimelineEntry{{ID: "t1", Time: "02:22", Actor: "SRE", Images: []Image{{Src: tinyPNG}, {Src: "javascript:alert(1)"}}}},
+		Branding:   Branding{Logo: tinyPNG},
+		Lang:       "pt",
+		Status:     StatusDraft,
+		StartTime:  "02:00",
+		EndTime:    "03:00",
+		References: "Runbook | https://wiki.example.com/runbook?a=1&b=2\nMirror | ftp://files.example.com/dump",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+	out := buf.String()
+
+	assert.Contains(t, out, `<html lang="pt">`)
+	assert.Contains(t, out, "Falha &lt;script&gt;")
+	assert.Contains(t, out, "Sumário Executivo")
+	assert.Contains(t, out, "SEV-1 (Crítico)")
+	assert.Contains(t, out, "RASCUNHO")
+	assert.Contains(t, out, `src="data:image/png;base64,`)
+	assert.NotContains(t, out, "javascript:")
+	assert.NotContains(t, out, "ZgotmplZ")
+	assert.Contains(t, out, `<li id="ref-2">Mirror | ftp://files.example.com/dump</li>`)
+	assert.Contains(t, out, `<li id="ref-1"><a href="https://wiki.example.com/runbook?a=1&amp;b=2">Runbook</a>`)
+}
+
+func TestReportStatusDefaultsToDraft(t *testing.T) {
+	data := PostmortemData{Title: "Checkout API Failure", Severity: "SEV-2", Lang: "en"}
+
+	var buf bytes.Buffer
+	require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+	assert.Contains(t, buf.String(), `<div class="status">Status: Draft</div>`)
+	assert.Contains(t, buf.String(), `<div class="watermark">DRAFT</div>`, "a report without a status is not final")
+	doc, _ := renderMarkdown(data, true)
+	assert.Contains(t, doc, "**Status:** Draft")
+
+	data.Status = StatusApproved
+	buf.Reset()
+	require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+	assert.Contains(t, buf.String(), `<div class="status">Status: Approved</div>`)
+	assert.NotContains(t, buf.String(), `<div class="watermark">`)
+}
+
+func TestHTMLIncidentMetrics(t *testing.T) {
+	data := PostmortemData{
+		Title:      "Checkout API Failure",
+		Severity:   "SEV-2",
+		Date:       "2024-05-01",
+		StartTime:  "23:00",
+		EndTime:    "02:30",
+		Milestones: Milestones{Detected: "23:05", Acknowledged: "23:12", Mitigated: "00:40"},
+		Lang:       "en",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+	out := buf.String()
+	assert.Contains(t, out, `<dt><abbr title="time to detect">TTD</abbr></dt><dd>0h 5m<br><small>time to detect</small></dd>`)
+	assert.Contains(t, out, `<abbr title="time to acknowledge">TTA</abbr></dt><dd>0h 7m`)
+	assert.Contains(t, out, `<abbr title="time to mitigate">TTM</abbr></dt><dd>1h 40m`)
+	assert.Contains(t, out, `<abbr title="time to resolve">TTR</abbr></dt><dd>3h 30m`)
+}
@@ -0,0 +1,349 @@
+package report
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// ImageOptions tune how images are prepared before they are embedded.
+type ImageOptions struct {
+	// DPI is the resolution images are scaled down to at their printed
+	// width, from 72 to 600; default 150. Images are never scaled up.
+	DPI int `json:"dpi,omitempty"`
+	// Quality is the JPEG quality, from 1 to 100; default 85.
+	Quality int `json:"quality,omitempty"`
+}
+
+const (
+	defaultImageDPI     = 150
+	defaultImageQuality = 85
+)
+
+// maxImagePixels bounds the width × height of accepted images. Decoding
+// takes about four bytes a pixel whatever the file size, so a small file
+// claiming huge dimensions could otherwise exhaust memory.
+const maxImagePixels = 50_000_000
+
+// ErrImageTooLarge is returned for images over maxImagePixels.
+var ErrImageTooLarge = errors.New("images are limited to 50 megapixels")
+
+func (o ImageOptions) dpi() int {
+	if o.DPI == 0 {
+		return defaultImageDPI
+	}
+	return o.DPI
+}
+
+func (o ImageOptions) quality() int {
+	if o.Quality == 0 {
+		return defaultImageQuality
+	}
+	return o.Quality
+}
+
+func getImageDimensions(imagePath string) (float64, float64) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	img, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, 0
+	}
+	return float64(img.Width), float64(img.Height)
+}
+
+// parseDataURL splits a base64 data URL into its MIME type and decoded bytes.
+func parseDataURL(dataURL string) (string, []byte, error) {
+	parts := strings.Split(dataURL, ",")
+	if len(parts) != 2 {
+		return "", nil, fmt.Errorf("invalid data URL")
+	}
+
+	// Extract MIME type, e.g., "data:image/png;base64" -> "image/png"
+	mimePart := strings.Split(parts[0], ";")[0]
+	mimeType := strings.TrimPrefix(mimePart, "data:")
+
+	decoded, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, err
+	}
+	return mimeType, decoded, nil
+}
+
+// imageExtension maps the image MIME types gofpdf can embed to a file extension.
+func imageExtension(mimeType string) string {
+	switch mimeType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	}
+	return ""
+}
+
+// supportedImage reports whether images of mimeType are accepted. The ones
+// gofpdf cannot embed are converted by normalizeImage.
+func supportedImage(mimeType string) bool {
+	switch mimeType {
+	case "image/png", "image/jpeg", "image/gif", "image/webp", "image/bmp", "image/tiff":
+		return true
+	}
+	return false
+}
+
+// normalizedImage is an image ready to embed in any format: a PNG or a
+// JPEG, upright, without metadata.
+type normalizedImage struct {
+	MimeType string
+	Data     []byte
+	W, H     int // pixels
+}
+
+// loadImage decodes a data URL image and normalizes it for printing at
+// widthMM millimeters.
+func loadImage(dataURL string, widthMM float64, opts ImageOptions) (normalizedImage, error) {
+	mimeType, decoded, err := parseDataURL(dataURL)
+	if err != nil {
+		return normalizedImage{}, err
+	}
+	if !supportedImage(mimeType) {
+		return normalizedImage{}, fmt.Errorf("unsupported image type %q", mimeType)
+	}
+	return normalizeImage(decoded, widthMM, opts)
+}
+
+// imageConfig reads the format and dimensions of an image from its header,
+// without decoding it, and rejects images over maxImagePixels.
+func imageConfig(b []byte) (image.Config, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return cfg, format, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return cfg, format, ErrImageTooLarge
+	}
+	return cfg, format, nil
+}
+
+// normalizeImage decodes an image, turns JPEGs upright by their EXIF
+// orientation, scales it down to opts.DPI at widthMM millimeters (when
+// widthMM is not zero) and encodes it again, which drops any metadata.
+// JPEGs and lossy WebPs become JPEGs at opts.Quality; everything else,
+// screenshots above all, becomes a PNG so text stays sharp.
+func normalizeImage(decoded []byte, widthMM float64, opts ImageOptions) (normalizedImage, error) {
+	if _, _, err := imageConfig(decoded); err != nil {
+		return normalizedImage{}, err
+	}
+	img, format, err := image.Decode(bytes.NewReader(decoded))
+	if err != nil {
+		return normalizedImage{}, err
+	}
+	photo := format == "jpeg"
+	if format == "webp" {
+		_, photo = img.(*image.YCbCr) // lossy and opaque
+	}
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(decoded))
+	}
+
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return normalizedImage{}, fmt.Errorf("empty image")
+	}
+	if maxW := int(math.Ceil(widthMM / 25.4 * float64(opts.dpi()))); widthMM > 0 && b.Dx() > maxW {
+		h := b.Dy() * maxW / b.Dx()
+		if h < 1 {
+			h = 1
+		}
+		dst := image.NewNRGBA(image.Rect(0, 0, maxW, h))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+		img = dst
+	}
+
+	var buf bytes.Buffer
+	out := normalizedImage{MimeType: "image/png", W: img.Bounds().Dx(), H: img.Bounds().Dy()}
+	if photo {
+		out.MimeType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.quality()})
+	} else {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, eightBit(img))
+	}
+	if err != nil {
+		return normalizedImage{}, err
+	}
+	out.Data = buf.Bytes()
+	return out, nil
+}
+
+// eightBit converts images with 16-bit or CMYK pixels, which gofpdf cannot
+// read back as PNG, to 8-bit RGBA.
+func eightBit(img image.Image) image.Image {
+	switch img.(type) {
+	case *image.NRGBA, *image.RGBA, *image.Gray, *image.Paletted:
+		return img
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation of a JPEG, from 1 (upright)
+// to 8, or 1 when there is none.
+func jpegOrientation(b []byte) int {
+	if len(b) < 4 || b[0] != 0xFF || b[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(b) && b[i] == 0xFF; {
+		marker := b[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan, end of image
+			break
+		}
+		size := int(binary.BigEndian.Uint16(b[i+2:]))
+		if size < 2 || i+2+size > len(b) {
+			break
+		}
+		if marker == 0xE1 {
+			if o := exifOrientation(b[i+4 : i+2+size]); o != 0 {
+				return o
+			}
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// exifOrientation finds the orientation tag in the first IFD of an APP1
+// segment, or returns 0.
+func exifOrientation(app1 []byte) int {
+	if len(app1) < 14 || string(app1[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+	t := app1[6:]
+	var order binary.ByteOrder
+	switch string(t[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(t[4:]))
+	if ifd < 8 || ifd+2 > len(t) {
+		return 0
+	}
+	n := int(order.Uint16(t[ifd:]))
+	for e := ifd + 2; n > 0 && e+12 <= len(t); e, n = e+12, n-1 {
+		if order.Uint16(t[e:]) == 0x0112 {
+			if o := int(order.Uint16(t[e+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orient turns an image with EXIF orientation o upright.
+func orient(img image.Image, o int) image.Image {
+	if o < 2 || o > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	if o >= 5 {
+		dst = image.NewNRGBA(image.Rect(0, 0, h, w))
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch o {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // upside down
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored upside down
+				dx, dy = x, h-1-y
+			case 5: // mirrored, turned left
+				dx, dy = y, x
+			case 6: // turned left
+				dx, dy = h-1-y, x
+			case 7: // mirrored, turned right
+				dx, dy = h-1-y, w-1-x
+			case 8: // turned right
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// decodeDataURLToTempImage normalizes a data URL image for printing at
+// widthMM millimeters and writes it to a temp file with the correct extension.
+func decodeDataURLToTempImage(dataURL string, widthMM float64, opts ImageOptions) string {
+	if dataURL == "" {
+		return ""
+	}
+
+	img, err := loadImage(dataURL, widthMM, opts)
+	if err != nil {
+		return ""
+	}
+
+	// Create a temp file with the correct extension
+	tmpfile, err := ioutil.TempFile("", "upload-*"+imageExtension(img.MimeType))
+	if err != nil {
+		return ""
+	}
+	defer tmpfile.Close()
+
+	if _, err := tmpfile.Write(img.Data); err != nil {
+		return ""
+	}
+
+	return tmpfile.Name()
+}
+
+// decodeDataURLToTempImageAndMeasure decodes a data URL image, stores it in a temp file,
+// and returns its temp path and the height (in mm) when scaled to targetWidth (in mm).
+func decodeDataURLToTempImageAndMeasure(pdf *gofpdf.Fpdf, dataURL string, targetWidth float64, opts ImageOptions) (string, float64) {
+	path := decodeDataURLToTempImage(dataURL, targetWidth, opts)
+	if path == "" {
+		return "", 0
+	}
+	wpx, hpx := getImageDimensions(path)
+	if wpx == 0 || hpx == 0 {
+		return path, 0
+	}
+	scale := targetWidth / wpx
+	return path, hpx * scale
+}
+
+func usableWidth(pdf *gofpdf.Fpdf, left, right float64) float64 {
+	pageW, _ := pdf.GetPageSize()
+	return pageW - left - right
+}
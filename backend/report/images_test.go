@@ -0,0 +1,128 @@
+package report
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// tinyWebP is a 75x100 lossless WebP.
+const tinyWebP = "data:image/webp;base64,UklGRrIBAABXRUJQVlA4TKUBAAAvSsAYAA8w//M///MfeJAkbXvaSG7m8Q3GfYSBJekwQztm/IcZlgwnmWImn2BK7aFmBtnVir6q//8VOkFE/xm4baTIu8c48ArEo6+B3zFKYln3pqClSCKX0begFTAXFOLXHSyF8cCNcZEG4OywuA4KVVfJCiArU7GAgJI8+lJP/OKMT/fBAjevg1cYB7YVkFuWga2lyPi5I0HFy5YTpWIHg0RZpkniRVW9odHAKOwosWuOGdxIyn2OvaCDvhg/we6TwadPBPbqBV58MsLmMJ8yZnOWk8SRz4N+QoyPL+MnamzMvcE1rHNEr91F9GKZPVUcS9w7PhhH36suB9qPeYb/oLk6cuTiJ0wOK3m5h1cKjW6EVZCYMK7dxcKCBdgP9HkKr9gkAO2P8GKZGWVdIAatQa+1IDpt6qyorVwdy01xdW8Jkfk6xjEXmVQQ+HQdFr6OKhIN34dXWq0+0qr6EJSCeeVLH9+gvGTLyqM65PQ44ihzlTXxQKjKbAvshXgir7Lil9w4L2bvMycmjQcqXaMCO6BlY28i+FOLzbfI1vEqxAhotocAAA=="
+
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	return img
+}
+
+// exifJPEG is a w by h JPEG with an EXIF segment giving its orientation.
+func exifJPEG(t *testing.T, w, h, orientation int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(w, h), nil))
+	exif := []byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08" + // big endian TIFF header, IFD at 8
+		"\x00\x01" + // one entry
+		"\x01\x12\x00\x03\x00\x00\x00\x01\x00" + string(rune(orientation)) + "\x00\x00" +
+		"\x00\x00\x00\x00")
+	segment := append([]byte{0xFF, 0xE1, 0, byte(len(exif) + 2)}, exif...)
+	return append(append([]byte{0xFF, 0xD8}, segment...), buf.Bytes()[2:]...)
+}
+
+func TestNormalizeImage(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(3000, 100)))
+	img, err := normalizeImage(buf.Bytes(), 100, ImageOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", img.MimeType)
+	assert.Equal(t, 591, img.W, "100mm at 150 DPI")
+	assert.Equal(t, 19, img.H)
+
+	img, err = normalizeImage(buf.Bytes(), 100, ImageOptions{DPI: 300})
+	require.NoError(t, err)
+	assert.Equal(t, 1182, img.W)
+
+	img, err = normalizeImage(buf.Bytes(), 0, ImageOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3000, img.W, "no width, no scaling")
+
+	t.Run("jpeg", func(t *testing.T) {
+		src := exifJPEG(t, 40, 20, 6)
+		assert.Equal(t, 6, jpegOrientation(src))
+		img, err := normalizeImage(src, 0, ImageOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", img.MimeType)
+		assert.Equal(t, []int{20, 40}, []int{img.W, img.H}, "turned upright")
+		assert.NotContains(t, string(img.Data), "Exif", "metadata is dropped")
+
+		low, err := normalizeImage(src, 0, ImageOptions{Quality: 10})
+		require.NoError(t, err)
+		assert.Less(t, len(low.Data), len(img.Data))
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		var b, tf bytes.Buffer
+		require.NoError(t, bmp.Encode(&b, testImage(8, 4)))
+		require.NoError(t, tiff.Encode(&tf, image.NewNRGBA64(image.Rect(0, 0, 8, 4)), nil))
+		_, webp, err := parseDataURL(tinyWebP)
+		require.NoError(t, err)
+
+		pdf := gofpdf.New("P", "mm", "A4", "")
+		for name, data := range map[string][]byte{"bmp": b.Bytes(), "tiff": tf.Bytes(), "webp": webp} {
+			img, err := normalizeImage(data, 50, ImageOptions{})
+			require.NoError(t, err, name)
+			assert.Equal(t, "image/png", img.MimeType, name)
+			// 16-bit TIFFs come out as 8-bit PNGs, which gofpdf can read.
+			pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(img.Data))
+			require.NoError(t, pdf.Error(), name)
+		}
+	})
+
+	_, err = normalizeImage([]byte("not an image"), 0, ImageOptions{})
+	assert.Error(t, err)
+}
+
+// bombPNG is the header of a 20000x20000 PNG: a few bytes that would take
+// 400 MB to decode.
+func bombPNG() []byte {
+	chunk := func(kind string, data []byte) []byte {
+		b := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+		b = append(append(b, kind...), data...)
+		return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b[4:]))
+	}
+	ihdr := binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, 20000), 20000)
+	ihdr = append(ihdr, 8, 0, 0, 0, 0) // 8-bit grayscale
+	b := append([]byte("\x89PNG\r\n\x1a\n"), chunk("IHDR", ihdr)...)
+	return append(b, chunk("IEND", nil)...)
+}
+
+func TestLoadImage(t *testing.T) {
+	img, err := loadImage(tinyWebP, 0, ImageOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []int{75, 100}, []int{img.W, img.H})
+
+	_, err = loadImage("data:image/svg+xml;base64,"+base64.StdEncoding.EncodeToString([]byte("<svg/>")), 0, ImageOptions{})
+	assert.Error(t, err)
+
+	bomb := "data:image/png;base64," + base64.StdEncoding.EncodeToString(bombPNG())
+	_, err = loadImage(bomb, 0, ImageOptions{})
+	assert.ErrorIs(t, err, ErrImageTooLarge, "the size is checked before decoding")
+
+	errs := Validate(PostmortemData{Title: "x", Severity: "SEV-3", Branding: Branding{Logo: bomb}})
+	require.Len(t, errs, 1)
+	assert.Equal(t, FieldError{Field: "branding.logo", Code: CodeImageTooLarge, Message: "The image is larger than 50 megapixels."}, errs[0])
+}
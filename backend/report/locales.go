@@ -1,4 +1,4 @@
-package main
+package report
 
 var translations = map[string]map[string]string{
 	"pt": {
@@ -22,6 +22,17 @@ var translations = map[string]map[string]string{
 		"Incident Response":           "Resposta ao Incidente",
 		"Communications":              "Comunicações",
 		"Timeline":                    "Linha do Tempo",
+		"Impact":                      "Impacto",
+		"Mitigation":                  "Mitigação",
+		"Before impact":               "Antes do impacto",
+		"Response":                    "Resposta",
+		"Recovery":                    "Recuperação",
+		"Resolved":                    "Resolvido",
+		"Metrics":                     "Métricas",
+		"Impact start":                "Início do impacto",
+		"Detected":                    "Detectado",
+		"Acknowledged":                "Reconhecido",
+		"Mitigated":                   "Mitigado",
 		"Dica: Clique no campo <strong>Time</strong> e use <strong>Ctrl+V</strong> para colar screenshots.": "Dica: Clique no campo <strong>Time</strong> e use <strong>Ctrl+V</strong> para colar screenshots.",
 		"Add Entry": "Adicionar Entrada",
 		"Cada item: time, actor, notes e imagens coladas": "Cada item: time, actor, notes e imagens coladas",
@@ -64,6 +75,42 @@ var translations = map[string]map[string]string{
 		"Start":                           "Início",
 		"End":                             "Fim",
 		"This report documents the incident occurrence, impact, response, and continuous improvement actions.": "Este relatório documenta a ocorrência, impacto, resposta e ações de melhoria contínua.",
+		"Draft":                   "Rascunho",
+		"In Review":               "Em Revisão",
+		"Approved":                "Aprovado",
+		"Published":               "Publicado",
+		"Figure":                  "Figura",
+		"Timezone":                "Fuso Horário",
+		"Table of Contents":       "Sumário",
+		"Page":                    "Página",
+		"of":                      "de",
+		"Generated":               "Gerado em",
+		"Overdue":                 "Atrasada",
+		"No actions recorded.":    "Nenhuma ação registrada.",
+		"time to detect":          "tempo para detectar",
+		"time to acknowledge":     "tempo para reconhecer",
+		"time to mitigate":        "tempo para mitigar",
+		"time to resolve":         "tempo para resolver",
+		"This field is required.": "Este campo é obrigatório.",
+		"Use one of SEV-1, SEV-2, SEV-3 or SEV-4.":                                                "Use SEV-1, SEV-2, SEV-3 ou SEV-4.",
+		"Use the YYYY-MM-DD date format.":                                                         "Use o formato de data AAAA-MM-DD.",
+		"Use the HH:MM 24-hour time format.":                                                      "Use o formato de hora HH:MM (24 horas).",
+		"Use an ISO 8601 date and time, e.g. 2024-05-01T23:10:00-03:00.":                          "Use data e hora ISO 8601, ex.: 2024-05-01T23:10:00-03:00.",
+		"Use an IANA timezone name, e.g. America/Sao_Paulo.":                                      "Use um nome de fuso horário IANA, ex.: America/Sao_Paulo.",
+		"The end must not be before the start.":                                                   "O fim não pode ser anterior ao início.",
+		"Milestones must follow impact start, detected, acknowledged, mitigated, resolved.":       "Os marcos devem seguir a ordem início do impacto, detecção, reconhecimento, mitigação, resolução.",
+		"Use \"cards\" or \"table\".":                                                             "Use \"cards\" ou \"table\".",
+		"Use a #RRGGBB hex color.":                                                                "Use uma cor hexadecimal #RRGGBB.",
+		"Use A4, Letter, Legal, A3 or A5, in portrait or landscape orientation.":                  "Use A4, Letter, Legal, A3 ou A5, em orientação retrato (portrait) ou paisagem (landscape).",
+		"Use \"actor\", \"phase\" or \"off\".":                                                    "Use \"actor\", \"phase\" ou \"off\".",
+		"Use \"full\", \"half\" or \"third\".":                                                    "Use \"full\", \"half\" ou \"third\".",
+		"Use a time column followed by numeric value columns, one row per time.":                  "Use uma coluna de tempo seguida de colunas de valores numéricos, uma linha por instante.",
+		"The image is not a valid PNG, JPEG, GIF, WebP, BMP or TIFF data URL or asset reference.": "A imagem não é uma data URL PNG, JPEG, GIF, WebP, BMP ou TIFF nem uma referência de asset válida.",
+		"Use a DPI between 72 and 600.":                                                           "Use um DPI entre 72 e 600.",
+		"Use a quality between 1 and 100.":                                                        "Use uma qualidade entre 1 e 100.",
+		"The image is larger than 50 megapixels.":                                                 "A imagem tem mais de 50 megapixels.",
+		"Use \"pt\" or \"en\".":                                                                   "Use \"pt\" ou \"en\".",
+		"Use draft, in_review, approved or published.":                                            "Use draft, in_review, approved ou published.",
 	},
 	"en": {
 		"Gerar Markdown":              "Generate Markdown",
@@ -87,6 +134,17 @@ var translations = map[string]map[string]string{
 		"Incident Response":           "Incident Response",
 		"Communications":              "Communications",
 		"Timeline":                    "Timeline",
+		"Impact":                      "Impact",
+		"Mitigation":                  "Mitigation",
+		"Before impact":               "Before impact",
+		"Response":                    "Response",
+		"Recovery":                    "Recovery",
+		"Resolved":                    "Resolved",
+		"Metrics":                     "Metrics",
+		"Impact start":                "Impact start",
+		"Detected":                    "Detected",
+		"Acknowledged":                "Acknowledged",
+		"Mitigated":                   "Mitigated",
 		"Dica: Clique no campo <strong>Time</strong> e use <strong>Ctrl+V</strong> para colar screenshots.": "Tip: Click on the <strong>Time</strong> field and use <strong>Ctrl+V</strong> to paste screenshots.",
 		"Add Entry": "Add Entry",
 		"Cada item: time, actor, notes e imagens coladas": "Each item: time, actor, notes and pasted images",
@@ -117,5 +175,41 @@ var translations = map[string]map[string]string{
 		"Start":                "Start",
 		"End":                  "End",
 		"This report documents the incident occurrence, impact, response, and continuous improvement actions.": "This report documents the incident occurrence, impact, response, and continuous improvement actions.",
+		"Draft":                   "Draft",
+		"In Review":               "In Review",
+		"Approved":                "Approved",
+		"Published":               "Published",
+		"Figure":                  "Figure",
+		"Timezone":                "Timezone",
+		"Table of Contents":       "Table of Contents",
+		"Page":                    "Page",
+		"of":                      "of",
+		"Generated":               "Generated",
+		"Overdue":                 "Overdue",
+		"No actions recorded.":    "No actions recorded.",
+		"time to detect":          "time to detect",
+		"time to acknowledge":     "time to acknowledge",
+		"time to mitigate":        "time to mitigate",
+		"time to resolve":         "time to resolve",
+		"This field is required.": "This field is required.",
+		"Use one of SEV-1, SEV-2, SEV-3 or SEV-4.":                                                "Use one of SEV-1, SEV-2, SEV-3 or SEV-4.",
+		"Use the YYYY-MM-DD date format.":                                                         "Use the YYYY-MM-DD date format.",
+		"Use the HH:MM 24-hour time format.":                                                      "Use the HH:MM 24-hour time format.",
+		"Use an ISO 8601 date and time, e.g. 2024-05-01T23:10:00-03:00.":                          "Use an ISO 8601 date and time, e.g. 2024-05-01T23:10:00-03:00.",
+		"Use an IANA timezone name, e.g. America/Sao_Paulo.":                                      "Use an IANA timezone name, e.g. America/Sao_Paulo.",
+		"The end must not be before the start.":                                                   "The end must not be before the start.",
+		"Milestones must follow impact start, detected, acknowledged, mitigated, resolved.":       "Milestones must follow impact start, detected, acknowledged, mitigated, resolved.",
+		"Use \"cards\" or \"table\".":                                                             "Use \"cards\" or \"table\".",
+		"Use a #RRGGBB hex color.":                                                                "Use a #RRGGBB hex color.",
+		"Use A4, Letter, Legal, A3 or A5, in portrait or landscape orientation.":                  "Use A4, Letter, Legal, A3 or A5, in portrait or landscape orientation.",
+		"Use \"actor\", \"phase\" or \"off\".":                                                    "Use \"actor\", \"phase\" or \"off\".",
+		"Use \"full\", \"half\" or \"third\".":                                                    "Use \"full\", \"half\" or \"third\".",
+		"Use a time column followed by numeric value columns, one row per time.":                  "Use a time column followed by numeric value columns, one row per time.",
+		"The image is not a valid PNG, JPEG, GIF, WebP, BMP or TIFF data URL or asset reference.": "The image is not a valid PNG, JPEG, GIF, WebP, BMP or TIFF data URL or asset reference.",
+		"Use a DPI between 72 and 600.":                                                           "Use a DPI between 72 and 600.",
+		"Use a quality between 1 and 100.":                                                        "Use a quality between 1 and 100.",
+		"The image is larger than 50 megapixels.":                                                 "The image is larger than 50 megapixels.",
+		"Use \"pt\" or \"en\".":                                                                   "Use \"pt\" or \"en\".",
+		"Use draft, in_review, approved or published.":                                            "Use draft, in_review, approved or published.",
 	},
 }
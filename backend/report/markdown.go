@@ -0,0 +1,381 @@
+package report
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MarkdownRenderer writes postmortems as Markdown. By default timeline images
+// are inlined as data URLs; with ExtractImages the output is instead a zip
+// archive holding the document and an images/ folder.
+type MarkdownRenderer struct {
+	ExtractImages bool
+	// Name is the base name of the .md file inside the zip. Defaults to "postmortem".
+	Name string
+}
+
+// MediaType implements Renderer.
+func (r MarkdownRenderer) MediaType() string {
+	if r.ExtractImages {
+		return "application/zip"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+// Extension implements Renderer.
+func (r MarkdownRenderer) Extension() string {
+	if r.ExtractImages {
+		return ".zip"
+	}
+	return ".md"
+}
+
+// Render implements Renderer.
+func (r MarkdownRenderer) Render(ctx context.Context, data PostmortemData, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	doc, images := renderMarkdown(data, !r.ExtractImages)
+	if !r.ExtractImages {
+		_, err := io.WriteString(w, doc)
+		return err
+	}
+	name := r.Name
+	if name == "" {
+		name = "postmortem"
+	}
+	return writeMarkdownZip(w, name+".md", doc, images)
+}
+
+// markdownImage is an image extracted from the report when images are
+// exported as separate files instead of inline data URLs.
+type markdownImage struct {
+	Name string
+	Data []byte
+}
+
+// markdownWriter builds the Markdown report section by section, mirroring
+// the order and wording of the PDF.
+type markdownWriter struct {
+	b      strings.Builder
+	lang   string
+	inline bool
+	images []markdownImage
+}
+
+// renderMarkdown turns data into a Markdown document. When inlineImages is
+// false, timeline images are referenced as images/figure-N.ext and returned
+// so the caller can ship them next to the document.
+func renderMarkdown(data PostmortemData, inlineImages bool) (string, []markdownImage) {
+	times := formatIncidentTimes(data)
+	m := &markdownWriter{lang: data.Lang, inline: inlineImages}
+
+	m.line("# %s", data.Title)
+	m.blank()
+	m.line("_%s - %s_", tr(m.lang, "Post-Incident Report"), times.Date)
+	m.blank()
+	m.line("**%s:** %s", tr(m.lang, "Status"), statusLabel(data.status(), m.lang))
+	m.blank()
+
+	m.heading(2, tr(m.lang, "Incident Overview"))
+	m.item(tr(m.lang, "Date (start)"), times.Date)
+	m.item(tr(m.lang, "Severity"), formatSeverity(data.Severity, m.lang))
+	m.item(tr(m.lang, "Duration"), times.Duration)
+	m.item(tr(m.lang, "Start"), withUTC(times.Start, times.StartUTC))
+	m.item(tr(m.lang, "End"), withUTC(times.End, times.EndUTC))
+	if data.Timezone != "" {
+		m.item(tr(m.lang, "Timezone"), data.Timezone)
+	}
+	for _, metric := range incidentMetrics(data) {
+		m.item(fmt.Sprintf("%s (%s)", metric.Label, metric.Name), metric.Value)
+	}
+	m.item(tr(m.lang, "Creator"), data.Creator)
+	m.item(strings.TrimSuffix(tr(m.lang, "Owners:"), ":"), data.Owners)
+	m.item(strings.TrimSuffix(tr(m.lang, "Affected Systems:"), ":"), data.Affected)
+	m.blank()
+
+	m.section(tr(m.lang, "Executive Summary"), data.Summary, data.Snippets.Summary)
+	m.section(tr(m.lang, "Customer Impact"), data.Impact, data.Snippets.Impact)
+	m.section(tr(m.lang, "Root Cause"), data.RootCause, data.Snippets.RootCause)
+	m.section(tr(m.lang, "Detection"), data.Detection, data.Snippets.Detection)
+	m.section(tr(m.lang, "Incident Response"), data.Response, data.Snippets.Response)
+	m.section(tr(m.lang, "Communications"), data.Comm, data.Snippets.Comm)
+
+	if len(data.Timeline) > 0 {
+		m.heading(2, tr(m.lang, "Timeline"))
+		figures := timelineFigures(data)
+		for i, entry := range data.Timeline {
+			m.heading(3, fmt.Sprintf("%s | %s %s", entry.Time, tr(m.lang, "Actor:"), entry.Actor))
+			m.rich(entry.Notes, 3)
+			for _, s := range entry.Snippets {
+				m.snippet(s)
+			}
+			for _, f := range figures[i] {
+				m.figure(f)
+			}
+		}
+	}
+
+	if len(data.Actions) > 0 {
+		m.heading(2, tr(m.lang, "Corrective & Preventive Actions (CAPA)"))
+		m.row(tr(m.lang, "Action"), tr(m.lang, "Owner"), tr(m.lang, "Priority"), tr(m.lang, "Due Date"), tr(m.lang, "Status"))
+		m.line("|---|---|---|---|---|")
+		now := time.Now()
+		for _, a := range data.Actions {
+			badges := badgesFor(a, m.lang, now)
+			priority, due, status := a.Priority, formatDate(a.Due, m.lang), a.Status
+			if badges.Priority != nil && badges.Priority.Kind == badgeUrgent {
+				priority = "**" + priority + "**"
+			}
+			if badges.Overdue != nil {
+				due += " **(" + badges.Overdue.Text + ")**"
+			}
+			if badges.Status != nil && badges.Status.Kind == badgeBlocked {
+				status = "**" + status + "**"
+			}
+			m.row(a.Action, a.Owner, priority, due, status)
+		}
+		m.blank()
+	}
+
+	if data.Lessons.Good != "" || data.Lessons.Improve != "" {
+		m.heading(2, tr(m.lang, "Lessons Learned"))
+		m.labeled(tr(m.lang, "What went well:"), data.Lessons.Good)
+		m.labeled(tr(m.lang, "What to improve:"), data.Lessons.Improve)
+	}
+
+	if refs := parseReferences(data.References); len(refs) > 0 {
+		m.heading(2, tr(m.lang, "References & Links"))
+		for _, ref := range refs {
+			if ref.URL == "" {
+				m.line("%d. %s", ref.Number, ref.Label)
+			} else {
+				m.line("%d. %s", ref.Number, markdownLink(escapeLinkText(ref.Label), ref.URL))
+			}
+		}
+		m.blank()
+	}
+
+	return m.b.String(), m.images
+}
+
+func (m *markdownWriter) line(format string, args ...interface{}) {
+	fmt.Fprintf(&m.b, format, args...)
+	m.b.WriteByte('\n')
+}
+
+func (m *markdownWriter) blank() {
+	m.b.WriteByte('\n')
+}
+
+func (m *markdownWriter) heading(level int, title string) {
+	m.line("%s %s", strings.Repeat("#", level), title)
+	m.blank()
+}
+
+func (m *markdownWriter) item(label, value string) {
+	if value == "" {
+		value = "-"
+	}
+	m.line("- **%s:** %s", label, value)
+}
+
+// section writes a titled block, skipping it entirely when it has neither
+// text nor snippets, just like the PDF's pdfWriter.section callers do.
+func (m *markdownWriter) section(title, content string, snippets []Snippet) {
+	if !hasContent(content, snippets) {
+		return
+	}
+	m.heading(2, title)
+	m.rich(content, 2)
+	for _, s := range snippets {
+		m.snippet(s)
+	}
+}
+
+// snippet writes a fenced code block, with its title in bold above it. The
+// fence is longer than any run of backticks in the code. Line numbers are
+// left to the viewer.
+func (m *markdownWriter) snippet(s Snippet) {
+	if s.Title != "" {
+		m.line("**%s**", s.Title)
+		m.blank()
+	}
+	fence := "```"
+	for strings.Contains(s.Code, fence) {
+		fence += "`"
+	}
+	m.line("%s%s", fence, strings.Join(strings.Fields(strings.ReplaceAll(s.Lang, "`", "")), " "))
+	m.line("%s", strings.TrimRight(s.Code, "\n"))
+	m.line("%s", fence)
+	m.blank()
+}
+
+// labeled writes a bold label followed by its paragraph, as in Lessons Learned.
+func (m *markdownWriter) labeled(label, content string) {
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+	m.line("**%s**", label)
+	m.blank()
+	m.rich(content, 2)
+}
+
+// rich writes a narrative field from its parsed blocks, as richHTML does
+// for HTML, so only the safe subset gets through: raw HTML is escaped and
+// links to URLs we do not render stay text. Headings are moved below the
+// level they are written under, so a "# Heading" typed in the summary does
+// not compete with the report title.
+func (m *markdownWriter) rich(text string, under int) {
+	blocks := parseRichText(text)
+	for i, block := range blocks {
+		indent := strings.Repeat("    ", block.Level)
+		switch block.Kind {
+		case blockHeading:
+			m.line("%s %s", strings.Repeat("#", min(block.Level+under, 6)), markdownSpans(block.Spans))
+		case blockBullet:
+			m.line("%s- %s", indent, markdownSpans(block.Spans))
+		case blockNumbered:
+			m.line("%s%d. %s", indent, block.Number, markdownSpans(block.Spans))
+		case blockCode:
+			m.snippet(Snippet{Lang: block.Lang, Code: block.Code})
+			continue
+		default:
+			m.line("%s", markdownParagraphIndent.ReplaceAllString(markdownSpans(block.Spans), ""))
+		}
+		// List items stay together, except before a nested list that does
+		// not start at 1: it could not interrupt the item's text.
+		if i+1 < len(blocks) && isListItem(block) && isListItem(blocks[i+1]) {
+			next := blocks[i+1]
+			if next.Kind == blockBullet || next.Number == 1 || next.Level <= block.Level {
+				continue
+			}
+		}
+		m.blank()
+	}
+}
+
+var (
+	// markdownLineStart matches what would start a heading, list or
+	// setext underline at the beginning of a line.
+	markdownLineStart = regexp.MustCompile(`(?m)^([ \t]*)([-+=#]|\d{1,9}[.)])`)
+	// markdownParagraphIndent would turn a paragraph into a code block.
+	markdownParagraphIndent = regexp.MustCompile(`(?m)^[ \t]+`)
+)
+
+// markdownSpans writes inline text back as Markdown, escaping whatever the
+// parser read as literal text.
+func markdownSpans(spans []richSpan) string {
+	var b strings.Builder
+	for _, s := range spans {
+		var text string
+		if s.Code {
+			text = markdownCode(s.Text)
+		} else {
+			text = markdownText(s.Text)
+		}
+		if s.Italic {
+			text = "*" + text + "*"
+		}
+		if s.Bold {
+			text = "**" + text + "**"
+		}
+		if s.URL != "" {
+			text = markdownLink(text, s.URL)
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;",
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "~", `\~`,
+)
+
+// markdownText escapes s so it reads as plain text, HTML included.
+func markdownText(s string) string {
+	s = markdownEscaper.Replace(s)
+	return markdownLineStart.ReplaceAllStringFunc(s, func(start string) string {
+		n := len(start) - 1
+		return start[:n] + `\` + start[n:]
+	})
+}
+
+// markdownCode is s as a code span, fenced with more backticks than it holds.
+func markdownCode(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// markdownLink links text to url, which must already be one referenceURL
+// accepts. Angle brackets are percent-encoded so the URL cannot end the
+// destination early.
+func markdownLink(text, url string) string {
+	return "[" + text + "](<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(url) + ">)"
+}
+
+func (m *markdownWriter) row(cells ...string) {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		c = strings.ReplaceAll(c, "|", `\|`)
+		c = strings.ReplaceAll(strings.TrimSpace(c), "\n", "<br>")
+		escaped[i] = c
+	}
+	m.line("| %s |", strings.Join(escaped, " | "))
+}
+
+// escapeLinkText keeps brackets in a label from closing the link early.
+func escapeLinkText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// figure writes f with its alt text, followed by its numbered caption in
+// italics. Markdown has no grid, so size hints are ignored.
+func (m *markdownWriter) figure(f figure) {
+	alt := escapeLinkText(f.alt(m.lang))
+	if m.inline {
+		m.line("![%s](data:%s;base64,%s)", alt, f.MimeType, base64.StdEncoding.EncodeToString(f.Data))
+	} else {
+		name := fmt.Sprintf("images/figure-%d%s", f.Number, imageExtension(f.MimeType))
+		m.images = append(m.images, markdownImage{Name: name, Data: f.Data})
+		m.line("![%s](%s)", alt, name)
+	}
+	m.blank()
+	m.line("*%s*", strings.ReplaceAll(f.label(m.lang), "*", `\*`))
+	m.blank()
+}
+
+// writeMarkdownZip packages the document and its extracted images in a zip archive.
+func writeMarkdownZip(w io.Writer, name, doc string, images []markdownImage) error {
+	zw := zip.NewWriter(w)
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, doc); err != nil {
+		return err
+	}
+	for _, img := range images {
+		f, err := zw.Create(img.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(img.Data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
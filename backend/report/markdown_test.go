@@ -0,0 +1,39 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 1x1 transparent PNG.
+const tinyPNG = "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+func TestRenderMarkdown(t *testing.T) {
+	data := PostmortemData{
+		Title:     "Checkout API Failure",
+		Severity:  "SEV-2",
+		StartTime: "02:00",
+		EndTime:   "03:30",
+		Summary:   "Checkout degraded.",
+		Timeline:  []TimelineEntry{{ID: "t1", Time: "02:22", Actor: "SRE", Notes: "Rollback", Images: []Image{{Src: tinyPNG}}}},
+		Actions:   []Action{{Action: "Fix | TTL", Owner: "Bob", Priority: "P1", Status: "Open"}},
+		Lang:      "en",
+	}
+
+	doc, images := renderMarkdown(data, true)
+	assert.True(t, strings.HasPrefix(doc, "# Checkout API Failure\n"))
+	assert.Contains(t, doc, "## Executive Summary\n\nCheckout degraded.")
+	assert.Contains(t, doc, "### 02:22 | Actor: SRE")
+	assert.Contains(t, doc, "| Fix \\| TTL | Bob | **P1** |")
+	assert.Contains(t, doc, "![Figure 1](data:image/png;base64,")
+	assert.NotContains(t, doc, "## Customer Impact")
+	assert.Empty(t, images)
+
+	doc, images = renderMarkdown(data, false)
+	require.Len(t, images, 1)
+	assert.Equal(t, "images/figure-1.png", images[0].Name)
+	assert.Contains(t, doc, "![Figure 1](images/figure-1.png)")
+}
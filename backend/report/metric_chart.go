@@ -0,0 +1,178 @@
+package report
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Metric chart geometry, in millimeters, shared by the PDF drawing and the
+// HTML SVG.
+const (
+	metricTitle  = 6.0  // title row
+	metricPlot   = 50.0 // plot area height
+	metricXLabel = 6.0  // tick labels under the plot
+	metricMark   = 3.5  // vertical step between staggered milestone labels
+	metricLine   = 0.5  // series line width
+	metricLevels = 3    // milestone label levels
+)
+
+// milestoneLabels name the milestones on charts, in lifecycle order.
+var milestoneLabels = []string{"Impact start", "Detected", "Acknowledged", "Mitigated", "Resolved"}
+
+// metricChart is a MetricChart laid out for drawing. Horizontal positions
+// are fractions of the time axis, from 0 at the left to 1 at the right, and
+// vertical ones fractions of the value axis, from 0 at the bottom to 1 at
+// the top.
+type metricChart struct {
+	Title  string
+	XTicks []chartTick
+	YTicks []chartTick
+	Bands  []chartBand
+	Marks  []chartMark
+	Lines  []chartLine
+	Legend []chartLegend
+}
+
+// chartMark is a milestone drawn as a dashed vertical line.
+type chartMark struct {
+	At    float64
+	Label string
+	Level int // label level, from 0 (top) to metricLevels-1
+}
+
+type chartLine struct {
+	Color  Color
+	Points []chartPoint
+}
+
+type chartPoint struct {
+	X, Y float64
+}
+
+// buildMetricChart lays out c. The time axis spans the data; milestones and
+// incident bands outside it are left out or cut at its ends. The value axis
+// starts at zero unless values go below it. ok is false when c has no point
+// to draw.
+func buildMetricChart(c MetricChart, data PostmortemData) (chart metricChart, ok bool) {
+	series, err := c.allSeries()
+	if err != nil {
+		return chart, false
+	}
+	reader := seriesReader{data}
+	var first, last time.Time
+	lo, hi := 0.0, math.Inf(-1)
+	points := make([][]timedPoint, len(series))
+	for i, s := range series {
+		points[i], _ = reader.read(s)
+		for _, p := range points[i] {
+			if first.IsZero() || p.Time.Before(first) {
+				first = p.Time
+			}
+			if p.Time.After(last) {
+				last = p.Time
+			}
+			lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+		}
+	}
+	if first.IsZero() {
+		return chart, false
+	}
+	if !last.After(first) {
+		first, last = first.Add(-30*time.Minute), last.Add(30*time.Minute)
+	}
+	if hi <= lo {
+		hi = lo + 1
+	}
+
+	chart.Title = c.Title
+	if c.Unit != "" {
+		chart.Title += " (" + c.Unit + ")"
+	}
+	at := func(t time.Time) float64 {
+		return float64(t.Sub(first)) / float64(last.Sub(first))
+	}
+	chart.XTicks = axisTicks(first, last, data.Lang)
+
+	lo, hi, values := valueTicks(lo, hi)
+	y := func(v float64) float64 { return (v - lo) / (hi - lo) }
+	for _, v := range values {
+		chart.YTicks = append(chart.YTicks, chartTick{y(v.value), v.label})
+	}
+
+	ms := lifecycle(data)
+	for _, b := range incidentBands(ms, data.Lang) {
+		from, to := math.Max(at(b.From), 0), math.Min(at(b.To), 1)
+		if from < to {
+			chart.Bands = append(chart.Bands, chartBand{from, to, b.Label, b.Fill})
+		}
+	}
+	for i, m := range ms {
+		if m.OK && !m.Time.Before(first) && !m.Time.After(last) {
+			chart.Marks = append(chart.Marks, chartMark{at(m.Time), tr(data.Lang, milestoneLabels[i]), len(chart.Marks) % metricLevels})
+		}
+	}
+
+	for i, s := range series {
+		if len(points[i]) == 0 {
+			continue
+		}
+		color := actorColors[i%len(actorColors)]
+		line := chartLine{Color: color}
+		for _, p := range points[i] {
+			line.Points = append(line.Points, chartPoint{at(p.Time), y(p.Value)})
+		}
+		chart.Lines = append(chart.Lines, line)
+		chart.Legend = append(chart.Legend, chartLegend{s.Name, color})
+	}
+	return chart, true
+}
+
+// metricCharts lays out the charts of data that have points to draw.
+func metricCharts(data PostmortemData) []metricChart {
+	var charts []metricChart
+	for _, c := range data.Charts {
+		if chart, ok := buildMetricChart(c, data); ok {
+			charts = append(charts, chart)
+		}
+	}
+	return charts
+}
+
+type valueTick struct {
+	value float64
+	label string
+}
+
+// valueTicks widens lo..hi to a round step that gives about five ticks, and
+// returns the new bounds with the ticks between them.
+func valueTicks(lo, hi float64) (float64, float64, []valueTick) {
+	step := niceStep((hi - lo) / 4)
+	lo, hi = math.Floor(lo/step)*step, math.Ceil(hi/step)*step
+	decimals := int(math.Max(0, -math.Floor(math.Log10(step))))
+
+	var ticks []valueTick
+	for i := 0; ; i++ {
+		v := lo + float64(i)*step
+		if v > hi+step/2 {
+			break
+		}
+		label := strconv.FormatFloat(v, 'f', decimals, 64)
+		if f, _ := strconv.ParseFloat(label, 64); f == 0 {
+			label = strconv.FormatFloat(0, 'f', decimals, 64) // not "-0"
+		}
+		ticks = append(ticks, valueTick{v, label})
+	}
+	return lo, hi, ticks
+}
+
+// niceStep rounds raw up to 1, 2 or 5 times a power of ten.
+func niceStep(raw float64) float64 {
+	exp := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, f := range []float64{1, 2, 5} {
+		if f*exp >= raw {
+			return f * exp
+		}
+	}
+	return 10 * exp
+}
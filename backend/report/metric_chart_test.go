@@ -0,0 +1,102 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func metricData() PostmortemData {
+	data := chartData()
+	data.Charts = []MetricChart{{
+		Title: "Error rate",
+		Unit:  "%",
+		CSV:   "time,checkout\n23:05,0.4\n23:15,12\n23:40,30\n00:10,4\n00:40,0.3\n",
+	}}
+	return data
+}
+
+func TestValueTicks(t *testing.T) {
+	lo, hi, ticks := valueTicks(0, 27)
+	assert.Equal(t, 0.0, lo)
+	assert.Equal(t, 30.0, hi)
+	var labels []string
+	for _, tick := range ticks {
+		labels = append(labels, tick.label)
+	}
+	assert.Equal(t, []string{"0", "10", "20", "30"}, labels)
+
+	_, _, ticks = valueTicks(-0.3, 0.1)
+	assert.Equal(t, "-0.3", ticks[0].label)
+	assert.Equal(t, "0.0", ticks[3].label, "no negative zero")
+}
+
+func TestBuildMetricChart(t *testing.T) {
+	data := metricData()
+	chart, ok := buildMetricChart(data.Charts[0], data)
+	require.True(t, ok)
+
+	assert.Equal(t, "Error rate (%)", chart.Title)
+	require.Len(t, chart.Lines, 1)
+	line := chart.Lines[0]
+	assert.Equal(t, chartPoint{0, 0.4 / 30}, line.Points[0])
+	assert.Equal(t, chartPoint{1, 0.01}, line.Points[4])
+	assert.Equal(t, 1.0, line.Points[2].Y, "the peak touches the top")
+	assert.Equal(t, []chartLegend{{"checkout", actorColors[0]}}, chart.Legend)
+
+	// The data runs from 23:05 to 00:40: the impact start (23:00) and the
+	// resolution (01:00) are outside it, so only their bands show, cut.
+	var marks []string
+	for _, m := range chart.Marks {
+		marks = append(marks, m.Label)
+	}
+	assert.Equal(t, []string{"Detected", "Mitigated"}, marks)
+	require.Len(t, chart.Bands, 2)
+	assert.Equal(t, 0.0, chart.Bands[0].From)
+	assert.Equal(t, 1.0, chart.Bands[1].To)
+
+	_, ok = buildMetricChart(MetricChart{Title: "empty", Series: []MetricSeries{{Name: "x"}}}, data)
+	assert.False(t, ok)
+
+	shuffled := MetricChart{Title: "Latency", Series: []MetricSeries{{Name: "p99", Points: []MetricPoint{
+		{Time: "2024-05-02T00:00", Value: 3},
+		{Time: "2024-05-01T23:00", Value: 1},
+		{Time: "2024-05-01T23:30", Value: 2},
+	}}}}
+	chart, ok = buildMetricChart(shuffled, data)
+	require.True(t, ok)
+	var xs []float64
+	for _, p := range chart.Lines[0].Points {
+		xs = append(xs, p.X)
+	}
+	assert.Equal(t, []float64{0, 0.5, 1}, xs, "points out of order are drawn in time order")
+}
+
+func TestMetricChartRenderers(t *testing.T) {
+	data := metricData()
+
+	t.Run("pdf", func(t *testing.T) {
+		chart, _ := buildMetricChart(data.Charts[0], data)
+		out := drawPDF(t, func(w *pdfWriter) { w.metricChart(chart) })
+		assert.Contains(t, out, "[2.83 2.83] 0.00 d", "milestones are dashed")
+		assert.Contains(t, out, "0.122 0.467 0.706 RG", "the series is stroked in its color")
+
+		var buf bytes.Buffer
+		require.NoError(t, PDFRenderer{}.Render(context.Background(), data, &buf))
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+		out := buf.String()
+		assert.Contains(t, out, "<h2 class=\"center\">Metrics</h2>")
+		assert.Contains(t, out, "<figcaption>Error rate (%)</figcaption>")
+		assert.Contains(t, out, `<polyline points="`)
+		assert.Contains(t, out, `text-anchor="start">Detected</text>`)
+		assert.Contains(t, out, `<span class="dot" style="background: #1F77B4"></span>checkout</li>`)
+	})
+}
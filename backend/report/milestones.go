@@ -0,0 +1,101 @@
+package report
+
+import (
+	"time"
+)
+
+// Milestones are the points of the incident lifecycle the response metrics
+// are measured from. Each accepts the same datetimes as StartAt, or an HH:MM
+// time read on the incident's start date (rolling over to the next day when
+// it would fall before the previous milestone).
+type Milestones struct {
+	ImpactStart  string `json:"impactStart,omitempty"`
+	Detected     string `json:"detected,omitempty"`
+	Acknowledged string `json:"acknowledged,omitempty"`
+	Mitigated    string `json:"mitigated,omitempty"`
+	Resolved     string `json:"resolved,omitempty"`
+}
+
+func (m Milestones) empty() bool {
+	return m == Milestones{}
+}
+
+// milestone is one entry of Milestones resolved to an instant.
+type milestone struct {
+	Field string // JSON name, used in validation errors
+	Value string
+	Time  time.Time
+	OK    bool
+}
+
+// resolveMilestones parses the milestones in lifecycle order. The result
+// always has five entries; OK is false for the ones left blank or unparseable.
+func resolveMilestones(data PostmortemData) []milestone {
+	m := data.Milestones
+	list := []milestone{
+		{Field: "impactStart", Value: m.ImpactStart},
+		{Field: "detected", Value: m.Detected},
+		{Field: "acknowledged", Value: m.Acknowledged},
+		{Field: "mitigated", Value: m.Mitigated},
+		{Field: "resolved", Value: m.Resolved},
+	}
+
+	clock := newClockReader(data)
+	for i := range list {
+		if ms := &list[i]; ms.Value != "" {
+			ms.Time, ms.OK = clock.read(ms.Value)
+		}
+	}
+	return list
+}
+
+// lifecycle is resolveMilestones with the incident start standing in for a
+// missing impact start, and the incident end for a missing resolution.
+func lifecycle(data PostmortemData) []milestone {
+	ms := resolveMilestones(data)
+	start, end, _ := incidentWindow(data)
+	if impact := &ms[0]; !impact.OK && !start.IsZero() {
+		impact.Time, impact.OK = start, true
+	}
+	if resolved := &ms[4]; !resolved.OK && !end.IsZero() {
+		resolved.Time, resolved.OK = end, true
+	}
+	return ms
+}
+
+// incidentMetric is one response metric ready for display.
+type incidentMetric struct {
+	Label string // short name, e.g. "TTD"
+	Name  string // localized description, e.g. "time to detect"
+	Value string
+}
+
+// incidentMetrics computes time to detect, acknowledge, mitigate and resolve.
+// Detection, mitigation and resolution are measured from the impact start
+// (the incident start when no impact start is given); acknowledgement is
+// measured from detection. Resolution falls back to the incident end.
+// Reports without milestones get no metrics, as they would only repeat the
+// duration.
+func incidentMetrics(data PostmortemData) []incidentMetric {
+	if data.Milestones.empty() {
+		return nil
+	}
+	ms := lifecycle(data)
+	impact, detected, acknowledged, mitigated, resolved := ms[0], ms[1], ms[2], ms[3], ms[4]
+
+	var metrics []incidentMetric
+	add := func(label, name string, from, to milestone) {
+		if from.OK && to.OK {
+			metrics = append(metrics, incidentMetric{
+				Label: label,
+				Name:  tr(data.Lang, name),
+				Value: formatDuration(to.Time.Sub(from.Time)),
+			})
+		}
+	}
+	add("TTD", "time to detect", impact, detected)
+	add("TTA", "time to acknowledge", detected, acknowledged)
+	add("TTM", "time to mitigate", impact, mitigated)
+	add("TTR", "time to resolve", impact, resolved)
+	return metrics
+}
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncidentMetrics(t *testing.T) {
+	data := PostmortemData{
+		Date:      "2024-05-01",
+		StartTime: "23:00",
+		EndTime:   "02:30",
+		Milestones: Milestones{
+			ImpactStart:  "22:50",
+			Detected:     "23:05",
+			Acknowledged: "23:12",
+			Mitigated:    "00:40", // the next day
+		},
+	}
+	assert.Equal(t, []incidentMetric{
+		{Label: "TTD", Name: "time to detect", Value: "0h 15m"},
+		{Label: "TTA", Name: "time to acknowledge", Value: "0h 7m"},
+		{Label: "TTM", Name: "time to mitigate", Value: "1h 50m"},
+		{Label: "TTR", Name: "time to resolve", Value: "3h 40m"}, // falls back to the incident end
+	}, incidentMetrics(data))
+
+	assert.Nil(t, incidentMetrics(PostmortemData{Date: "2024-05-01", StartTime: "23:00", EndTime: "02:30"}))
+
+	zoned := PostmortemData{
+		Lang:     "pt",
+		Timezone: "America/Sao_Paulo",
+		Milestones: Milestones{
+			ImpactStart: "2024-05-01T23:00",
+			Resolved:    "2024-05-02T02:00:00Z", // 23:00 in São Paulo
+		},
+	}
+	assert.Equal(t, []incidentMetric{{Label: "TTR", Name: "tempo para resolver", Value: "0h 0m"}}, incidentMetrics(zoned))
+
+	// Milestones are read like timeline entries: an HH:MM follows the day
+	// of the milestone before it.
+	days := PostmortemData{
+		Date:       "2024-05-01",
+		StartTime:  "23:00",
+		Milestones: Milestones{Mitigated: "2024-05-03T08:00", Resolved: "09:00"},
+	}
+	assert.Equal(t, []incidentMetric{
+		{Label: "TTM", Name: "time to mitigate", Value: "1d 9h 0m"},
+		{Label: "TTR", Name: "time to resolve", Value: "1d 10h 0m"},
+	}, incidentMetrics(days))
+}
+
+func TestValidateMilestones(t *testing.T) {
+	data := PostmortemData{
+		Title:    "Checkout API Failure",
+		Severity: "SEV-2",
+		Milestones: Milestones{
+			ImpactStart: "2024-05-01T10:00",
+			Detected:    "2024-05-01T09:00",
+			Mitigated:   "soon",
+		},
+	}
+	codes := map[string]string{}
+	for _, e := range Validate(data) {
+		codes[e.Field] = e.Code
+	}
+	assert.Equal(t, map[string]string{
+		"milestones.detected":  CodeMilestoneOrder,
+		"milestones.mitigated": CodeInvalidDateTime,
+	}, codes)
+}
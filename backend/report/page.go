@@ -0,0 +1,65 @@
+package report
+
+import "strings"
+
+// Page orientations accepted in PageOptions.Orientation.
+const (
+	OrientationPortrait  = "portrait"
+	OrientationLandscape = "landscape"
+)
+
+// PageOptions choose the paper of the paged formats: PDF, DOCX and the
+// printed HTML page.
+type PageOptions struct {
+	Size        string `json:"size,omitempty"`        // A4 (default), Letter, Legal, A3 or A5
+	Orientation string `json:"orientation,omitempty"` // OrientationPortrait (default) or OrientationLandscape
+}
+
+// pageSize is a paper size in portrait orientation, in millimeters.
+type pageSize struct {
+	Name string // as written in CSS @page rules
+	W, H float64
+}
+
+// pageSizes are keyed by lower-case name.
+var pageSizes = map[string]pageSize{
+	"a3":     {"A3", 297, 420},
+	"a4":     {"A4", 210, 297},
+	"a5":     {"A5", 148, 210},
+	"letter": {"letter", 215.9, 279.4},
+	"legal":  {"legal", 215.9, 355.6},
+}
+
+// valid reports whether the size and orientation are known or blank.
+func (p PageOptions) valid() (size, orientation bool) {
+	_, known := pageSizes[strings.ToLower(p.Size)]
+	size = p.Size == "" || known
+	switch strings.ToLower(p.Orientation) {
+	case "", OrientationPortrait, OrientationLandscape:
+		orientation = true
+	}
+	return size, orientation
+}
+
+// landscape reports whether the page is wider than tall.
+func (p PageOptions) landscape() bool {
+	return strings.EqualFold(p.Orientation, OrientationLandscape)
+}
+
+// size is the paper size in portrait orientation; unknown sizes are A4.
+func (p PageOptions) size() pageSize {
+	size, ok := pageSizes[strings.ToLower(p.Size)]
+	if !ok {
+		size = pageSizes["a4"]
+	}
+	return size
+}
+
+// paper is the page size in the requested orientation.
+func (p PageOptions) paper() pageSize {
+	size := p.size()
+	if p.landscape() {
+		size.W, size.H = size.H, size.W
+	}
+	return size
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageOptions(t *testing.T) {
+	assert.Equal(t, pageSize{"A4", 210, 297}, PageOptions{}.paper())
+	assert.Equal(t, pageSize{"letter", 279.4, 215.9}, PageOptions{Size: "Letter", Orientation: "landscape"}.paper())
+
+	size, orientation := PageOptions{Size: "B5", Orientation: "sideways"}.valid()
+	assert.False(t, size)
+	assert.False(t, orientation)
+	size, orientation = PageOptions{Size: "legal", Orientation: "Portrait"}.valid()
+	assert.True(t, size)
+	assert.True(t, orientation)
+}
+
+func TestRenderLandscapeLetter(t *testing.T) {
+	data := PostmortemData{
+		Title:    "Landscape",
+		Timeline: []TimelineEntry{{ID: "t1", Time: "02:22", Notes: "n", Images: []Image{{Src: tinyPNG}}}},
+		Actions:  []Action{{Action: "Add TTL test", Owner: "Bob"}},
+		Options:  Options{Page: PageOptions{Size: "Letter", Orientation: OrientationLandscape}, CAPALayout: CAPALayoutTable},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, PDFRenderer{}.Render(context.Background(), data, &buf))
+	// 279.4 x 215.9 mm is 792 x 612 pt.
+	assert.Contains(t, buf.String(), "/MediaBox [0 0 792.00 612.00]")
+
+	buf.Reset()
+	require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+	assert.Contains(t, buf.String(), "@page { size: letter landscape; }")
+
+	buf.Reset()
+	require.NoError(t, DOCXRenderer{}.Render(context.Background(), data, &buf))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			doc, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Contains(t, string(doc), `<w:pgSz w:w="15840" w:h="12240" w:orient="landscape"/>`)
+		}
+	}
+}
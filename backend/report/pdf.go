@@ -0,0 +1,780 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// PDFRenderer lays out postmortems as PDF documents with gofpdf.
+type PDFRenderer struct {
+	// Fonts are the typefaces themes can use and the fallback chain draws
+	// from. Defaults to DefaultFonts, or to the fonts in FontDir when set.
+	Fonts *FontLibrary
+	// FontDir is a directory of extra .ttf fonts, read on every render.
+	// Servers should load a FontLibrary once instead.
+	FontDir string
+}
+
+// MediaType implements Renderer.
+func (PDFRenderer) MediaType() string { return "application/pdf" }
+
+// Extension implements Renderer.
+func (PDFRenderer) Extension() string { return ".pdf" }
+
+// library is the FontLibrary r renders with.
+func (r PDFRenderer) library() (*FontLibrary, error) {
+	switch {
+	case r.Fonts != nil:
+		return r.Fonts, nil
+	case r.FontDir != "":
+		return LoadFonts(r.FontDir)
+	}
+	return DefaultFonts(), nil
+}
+
+// pdfWriter draws a postmortem with the colors, sizes and spacing of its theme.
+type pdfWriter struct {
+	pdf   *gofpdf.Fpdf
+	theme Theme
+	lang  string
+
+	fonts      []*fontFamily   // fallback chain; the first is the theme's font
+	mono       []*fontFamily   // fallback chain for code, starting with the theme's mono font
+	registered map[string]bool // gofpdf font keys added so far
+	style      string
+	size       float64
+	code       bool // set in the mono chain
+}
+
+func newPDFWriter(pdf *gofpdf.Fpdf, th Theme, lang string, lib *FontLibrary) *pdfWriter {
+	return &pdfWriter{pdf: pdf, theme: th, lang: lang,
+		fonts: lib.chain(th.Fonts),
+		mono:  lib.chain(ThemeFonts{Family: th.Fonts.Mono, Fallback: []string{DefaultMonoFontFamily}}),
+	}
+}
+
+func (w *pdfWriter) textColor(c Color) { w.pdf.SetTextColor(c.RGB()) }
+func (w *pdfWriter) fillColor(c Color) { w.pdf.SetFillColor(c.RGB()) }
+func (w *pdfWriter) drawColor(c Color) { w.pdf.SetDrawColor(c.RGB()) }
+
+// lh scales a line height by the theme's line spacing.
+func (w *pdfWriter) lh(h float64) float64 {
+	return h * w.theme.LineSpacing
+}
+
+// textWidth is the width between the left and right margins.
+func (w *pdfWriter) textWidth() float64 {
+	left, _, right, _ := w.pdf.GetMargins()
+	pageW, _ := w.pdf.GetPageSize()
+	return pageW - left - right
+}
+
+// textHeight is the height between the top and bottom margins.
+func (w *pdfWriter) textHeight() float64 {
+	_, top, _, _ := w.pdf.GetMargins()
+	_, pageH := w.pdf.GetPageSize()
+	_, bottom := w.pdf.GetAutoPageBreak()
+	return pageH - top - bottom
+}
+
+// divider draws a rule across the text width, inset from both margins.
+func (w *pdfWriter) divider(inset float64) {
+	left, _, _, _ := w.pdf.GetMargins()
+	y := w.pdf.GetY()
+	w.drawColor(w.theme.Palette.Rule)
+	w.pdf.Line(left+inset, y, left+w.textWidth()-inset, y)
+}
+
+// Render implements Renderer.
+func (r PDFRenderer) Render(ctx context.Context, data PostmortemData, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	lib, err := r.library()
+	if err != nil {
+		return err
+	}
+
+	times := formatIncidentTimes(data)
+	th := data.Options.Theme.theme()
+	sizes, palette := th.Sizes, th.Palette
+
+	paper := data.Options.Page.size()
+	orientation := "P"
+	if data.Options.Page.landscape() {
+		orientation = "L"
+	}
+	// O gofpdf recebe o papel em retrato e o gira sozinho quando a orientação é paisagem
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: orientation,
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: paper.W, Ht: paper.H},
+	})
+	w := newPDFWriter(pdf, th, data.Lang, lib)
+	topMargin := th.Margins.Top
+	leftMargin := th.Margins.Left
+	rightMargin := th.Margins.Right
+	bottomMargin := th.Margins.Bottom
+
+	pdf.SetMargins(leftMargin, topMargin, rightMargin)
+	pdf.SetAutoPageBreak(true, bottomMargin)
+
+	headerImgPath, _ := decodeDataURLToTempImageAndMeasure(pdf, data.Branding.Header, usableWidth(pdf, leftMargin, rightMargin), data.Options.Images)
+	footerImgPath, footerH := decodeDataURLToTempImageAndMeasure(pdf, data.Branding.Footer, usableWidth(pdf, leftMargin, rightMargin), data.Options.Images)
+	logoImgPath, _ := decodeDataURLToTempImageAndMeasure(pdf, data.Branding.Logo, usableWidth(pdf, leftMargin, rightMargin), data.Options.Images)
+	defer func() {
+		for _, path := range []string{headerImgPath, footerImgPath, logoImgPath} {
+			if path != "" {
+				os.Remove(path)
+			}
+		}
+	}()
+
+	if footerImgPath != "" {
+		pdf.SetAutoPageBreak(true, bottomMargin+footerH+5)
+	}
+
+	pdf.SetHeaderFuncMode(func() {
+		w.stampStatus(data.status())
+		if pdf.PageNo() == 1 || headerImgPath == "" {
+			return
+		}
+
+		info := pdf.RegisterImage(headerImgPath, "")
+		iw, ih := info.Extent() // dimensões originais da imagem
+
+		// Pega tamanho da página completo (não apenas área útil)
+		pageW, _ := pdf.GetPageSize()
+
+		// Calcula altura proporcional à largura total da página
+		scale := pageW / iw
+		hScaled := ih * scale
+
+		// Renderiza a imagem ocupando 100% da largura da página
+		pdf.ImageOptions(headerImgPath, 0, 0, pageW, 0, false, gofpdf.ImageOptions{}, 0, "")
+
+		// Ajusta a margem superior pra não sobrepor o texto
+		pdf.SetTopMargin(hScaled + 10)
+	}, true)
+
+	now := time.Now()
+	footerLeft, footerRight := footerText(data, now)
+	pdf.AliasNbPages(pageNumberAlias)
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+
+		pageW, pageH := pdf.GetPageSize()
+		textY := pageH - 10
+
+		if footerImgPath != "" {
+			// Detecta dimensões originais da imagem
+			info := pdf.RegisterImage(footerImgPath, "")
+			iw, ih := info.Extent()
+
+			// Calcula escala proporcional à largura total da página
+			scale := pageW / iw
+			hScaled := ih * scale
+
+			// Desenha imagem ocupando 100% da largura
+			y := pageH - hScaled
+			pdf.ImageOptions(footerImgPath, 0, y, pageW, 0, false, gofpdf.ImageOptions{}, 0, "")
+
+			// O texto fica logo acima da imagem, no espaço reservado pela margem inferior
+			textY = y - 7
+		}
+
+		// Classificação e ID à esquerda, "Página X de Y" ao centro, data de geração à direita
+		w.keepFont(func() {
+			left, _, _, _ := pdf.GetMargins()
+			width := w.textWidth()
+			w.font("", sizes.Small)
+			w.textColor(palette.Muted)
+			pdf.SetXY(left, textY)
+			w.cell(width, 5, footerLeft, "", 0, "L", false, 0)
+			pdf.SetXY(left, textY)
+			w.cell(width, 5, footerRight, "", 0, "R", false, 0)
+			pdf.SetXY(left, textY)
+			w.font("", sizes.Small+1)
+			w.cell(width, 5, pageLabel(strconv.Itoa(pdf.PageNo()), pageNumberAlias, data.Lang), "", 0, "C", false, 0)
+		})
+		w.textColor(palette.Text)
+	})
+	// Cover Page
+	pdf.AddPage()
+	w.textColor(palette.Text)
+	if logoImgPath != "" {
+		pageW, pageH := pdf.GetPageSize()
+		logoW := math.Min(pageW, pageH) * 0.35
+		x := (pageW - logoW) / 2
+		y := pageH * 0.25
+		pdf.ImageOptions(logoImgPath, x, y, logoW, 0, false, gofpdf.ImageOptions{}, 0, "")
+	}
+
+	// ====== CAPA ======
+	_, coverH := pdf.GetPageSize()
+	pdf.SetY(coverH * 0.37)
+	w.font("B", sizes.Title)
+	w.multiCell(0, 10, data.Title, "C")
+	pdf.Ln(10)
+
+	w.font("", sizes.Body)
+	w.multiCell(0, 8,
+		fmt.Sprintf("%s - %s",
+			tr(data.Lang, "Post-Incident Report"),
+			times.Date,
+		),
+		"C",
+	)
+	w.multiCell(0, 8,
+		fmt.Sprintf("%s: %s",
+			tr(data.Lang, "Severity"),
+			formatSeverity(data.Severity, data.Lang),
+		),
+		"C",
+	)
+	w.multiCell(0, 8,
+		fmt.Sprintf("%s: %s",
+			tr(data.Lang, "Creator"),
+			data.Creator,
+		),
+		"C",
+	)
+	pdf.Ln(20)
+
+	// Sumário: as páginas são reservadas aqui e preenchidas no final, quando os números de página já são conhecidos
+	toc := &pdfTOC{pdfWriter: w}
+	toc.reserve(tocLength(data))
+
+	// ====== PÓS-CAPA: RESUMO DO INCIDENTE =====
+	pdf.AddPage()
+	// === VISÃO GERAL DO INCIDENTE (cor primária com texto branco) ===
+	toc.mark(tr(data.Lang, "Incident Overview"))
+	w.font("B", sizes.Heading)
+	w.cell(0, 12, tr(data.Lang, "Incident Overview"), "", 1, "C", false, 0)
+	pdf.Ln(10)
+
+	pdf.SetLineWidth(0.3)
+
+	xStart := leftMargin
+	yStart := pdf.GetY()
+	colGap := 10.0
+	colWidth := (w.textWidth() - colGap) / 2
+	rowH := 9.0
+	lineH := 5.0
+
+	w.drawColor(palette.Rule)
+
+	// Função pra desenhar uma linha (rótulo colorido, valor branco); devolve a altura usada
+	drawRow := func(x, y float64, label string, values ...string) float64 {
+		labelW := 35.0
+		valueW := colWidth - labelW
+		h := rowH
+		if len(values) > 1 {
+			h = float64(len(values))*lineH + 4
+		}
+
+		// rótulo na cor primária
+		w.fillColor(palette.Primary)
+		pdf.SetTextColor(255, 255, 255)
+		pdf.RoundedRect(x, y, labelW, h, 0, "1234", "DF")
+		pdf.SetXY(x+3, y+2)
+		w.font("B", sizes.Body)
+		w.cell(labelW-6, lineH, label, "", 0, "L", false, 0)
+
+		// valor branco
+		pdf.SetFillColor(255, 255, 255)
+		w.textColor(palette.Text)
+		pdf.Rect(x+labelW, y, valueW, h, "D")
+		w.font("", sizes.Body)
+		for i, value := range values {
+			if i > 0 {
+				w.font("", sizes.Small)
+				w.textColor(palette.Muted)
+			}
+			pdf.SetXY(x+labelW+3, y+2+float64(i)*lineH)
+			w.cell(valueW-6, lineH, value, "", 0, "L", false, 0)
+		}
+		w.textColor(palette.Text)
+		return h
+	}
+
+	// Coluna 1
+	col1X := xStart
+	col1Y := yStart
+	col1Y += drawRow(col1X, col1Y, tr(data.Lang, "Date (start)"), times.Date)
+	col1Y += drawRow(col1X, col1Y, tr(data.Lang, "Severity"), formatSeverity(data.Severity, data.Lang))
+	col1Y += drawRow(col1X, col1Y, tr(data.Lang, "Duration"), times.Duration)
+	if data.Timezone != "" {
+		col1Y += drawRow(col1X, col1Y, tr(data.Lang, "Timezone"), data.Timezone)
+	}
+
+	// Coluna 2 (horário do incidente e, abaixo, em UTC)
+	col2X := xStart + colWidth + colGap
+	col2Y := yStart
+	col2Y += drawRow(col2X, col2Y, tr(data.Lang, "Start"), nonEmpty(times.Start, times.StartUTC)...)
+	col2Y += drawRow(col2X, col2Y, tr(data.Lang, "End"), nonEmpty(times.End, times.EndUTC)...)
+	for _, m := range incidentMetrics(data) {
+		col2Y += drawRow(col2X, col2Y, m.Label, m.Value, m.Name)
+	}
+
+	// Avança o cursor
+	pdf.SetY(math.Max(col1Y, col2Y) + 10)
+	w.multiCell(0, w.lh(6), fmt.Sprintf("%s %s", tr(data.Lang, "Owners:"), data.Owners), "")
+	pdf.Ln(10)
+
+	w.divider(0)
+	pdf.Ln(8)
+
+	if hasContent(data.Summary, data.Snippets.Summary) {
+		w.section(toc, tr(data.Lang, "Executive Summary"), data.Summary, data.Snippets.Summary)
+	}
+	if hasContent(data.Impact, data.Snippets.Impact) {
+		w.section(toc, tr(data.Lang, "Customer Impact"), data.Impact, data.Snippets.Impact)
+	}
+
+	w.divider(0)
+	pdf.Ln(8)
+
+	w.section(toc, "", tr(data.Lang, "This report documents the incident occurrence, impact, response, and continuous improvement actions."), nil)
+
+	pdf.AddPage()
+
+	w.font("B", sizes.Title*1.1)
+	w.multiCell(0, 10, data.Title, "C")
+	pdf.Ln(15)
+
+	toc.mark(tr(data.Lang, "Incident Details"))
+	w.font("B", sizes.Section)
+	w.cell(0, 10, tr(data.Lang, "Incident Details"), "", 0, "", false, 0)
+	pdf.Ln(10)
+
+	w.font("", sizes.Body)
+	w.multiCell(0, w.lh(7), fmt.Sprintf("%s %s", tr(data.Lang, "Owners:"), data.Owners), "")
+	w.multiCell(0, w.lh(7), fmt.Sprintf("%s %s", tr(data.Lang, "Affected Systems:"), data.Affected), "")
+	pdf.Ln(10)
+
+	toc.mark(tr(data.Lang, "Technical Problems"))
+	w.font("B", sizes.Section)
+	w.cell(0, 10, tr(data.Lang, "Technical Problems"), "", 0, "", false, 0)
+	pdf.Ln(10)
+
+	w.font("", sizes.Body)
+	w.richText(w.lh(7), "", data.RootCause)
+	pdf.Ln(10)
+
+	// Dynamic Sections
+
+	if hasContent(data.RootCause, data.Snippets.RootCause) {
+		w.section(toc, tr(data.Lang, "Root Cause"), data.RootCause, data.Snippets.RootCause)
+	}
+	if hasContent(data.Detection, data.Snippets.Detection) {
+		w.section(toc, tr(data.Lang, "Detection"), data.Detection, data.Snippets.Detection)
+	}
+	if hasContent(data.Response, data.Snippets.Response) {
+		w.section(toc, tr(data.Lang, "Incident Response"), data.Response, data.Snippets.Response)
+	}
+	if hasContent(data.Comm, data.Snippets.Comm) {
+		w.section(toc, tr(data.Lang, "Communications"), data.Comm, data.Snippets.Comm)
+	}
+
+	// Timeline
+	// ==== TIMELINE ESTILIZADA (sem boxes, hierarquia visual limpa) ====
+	if len(data.Timeline) > 0 {
+		toc.mark(tr(data.Lang, "Timeline"))
+		w.font("B", sizes.Section)
+		w.cell(0, 10, tr(data.Lang, "Timeline"), "", 1, "C", false, 0)
+		pdf.Ln(4)
+
+		// Gráfico da linha do tempo, antes da lista
+		if chart, ok := buildTimelineChart(data); ok {
+			w.timelineChart(chart)
+			pdf.Ln(6)
+		}
+
+		pdf.SetLineWidth(0.3)
+
+		figures := timelineFigures(data)
+		for i, entry := range data.Timeline {
+			// Linha separadora (menos na primeira)
+			if i > 0 {
+				w.divider(5)
+				pdf.Ln(4)
+			}
+
+			// Cabeçalho do evento (também vira marcador, abaixo da Linha do Tempo)
+			pdf.Bookmark(strings.TrimSpace(entry.Time+" "+entry.Actor), 1, -1)
+			w.font("B", sizes.Subsection)
+			w.textColor(palette.Accent)
+			w.cell(0, 6, fmt.Sprintf(" %s  |  %s %s", entry.Time, tr(data.Lang, "Actor:"), entry.Actor), "", 1, "L", false, 0)
+			w.textColor(palette.Text)
+
+			// Notas
+			w.font("", sizes.Body)
+			w.richText(w.lh(6), tr(data.Lang, "Notes:"), entry.Notes)
+			pdf.Ln(3)
+
+			// Snippets (logs, saídas de comandos, diffs)
+			for _, s := range entry.Snippets {
+				w.snippet(s)
+				pdf.Ln(3)
+			}
+
+			// Figuras, em grade e numeradas
+			if len(figures[i]) > 0 {
+				w.figures(figures[i], data.Lang)
+			}
+		}
+		pdf.Ln(8)
+	}
+
+	// ==== MÉTRICAS (gráficos das séries temporais) ====
+	if charts := metricCharts(data); len(charts) > 0 {
+		toc.mark(tr(data.Lang, "Metrics"))
+		w.font("B", sizes.Section)
+		w.cell(0, 10, tr(data.Lang, "Metrics"), "", 1, "C", false, 0)
+		pdf.Ln(4)
+		for _, chart := range charts {
+			w.metricChart(chart)
+			pdf.Ln(8)
+		}
+	}
+
+	// ==== AÇÕES CORRETIVAS E PREVENTIVAS (CAPA) ====
+	if len(data.Actions) > 0 {
+		toc.mark(tr(data.Lang, "Corrective & Preventive Actions (CAPA)"))
+		w.band(tr(data.Lang, "Corrective & Preventive Actions (CAPA)"))
+		pdf.Ln(5)
+
+		if data.Options.CAPALayout == CAPALayoutTable {
+			w.actionsTable(data.Actions, now)
+			pdf.Ln(10)
+		} else {
+			w.actionCards(data.Actions, now)
+		}
+	}
+
+	// Lessons Learned
+	if data.Lessons.Good != "" || data.Lessons.Improve != "" {
+		toc.mark(tr(data.Lang, "Lessons Learned"))
+		w.band(tr(data.Lang, "Lessons Learned"))
+
+		pdf.Ln(10)
+
+		if data.Lessons.Good != "" {
+			w.font("B", sizes.Subsection)
+			w.cell(0, 7, tr(data.Lang, "What went well:"), "", 0, "", false, 0)
+			pdf.Ln(7)
+			w.font("", sizes.Body)
+			w.richText(w.lh(7), "", data.Lessons.Good)
+			pdf.Ln(5)
+		}
+
+		if data.Lessons.Improve != "" {
+			w.font("B", sizes.Subsection)
+			w.cell(0, 7, tr(data.Lang, "What to improve:"), "", 0, "", false, 0)
+			pdf.Ln(7)
+			w.font("", sizes.Body)
+			w.richText(w.lh(7), "", data.Lessons.Improve)
+			pdf.Ln(10)
+		}
+	}
+
+	// Appendix - References & Links
+	if refs := parseReferences(data.References); len(refs) > 0 {
+		w.references(toc, refs)
+	}
+
+	toc.draw()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return pdf.Output(out)
+}
+
+// stampStatus writes the lifecycle state in the top-right corner and, until
+// the report is approved, a diagonal watermark behind the page content.
+func (w *pdfWriter) stampStatus(status string) {
+	w.keepFont(func() {
+		pdf := w.pdf
+		pageW, pageH := pdf.GetPageSize()
+		label := statusLabel(status, w.lang)
+
+		if needsWatermark(status) {
+			w.font("B", 72)
+			pdf.SetTextColor(235, 235, 235)
+			text := strings.ToUpper(label)
+			textW := pdf.GetStringWidth(text)
+			cx, cy := pageW/2, pageH/2
+			pdf.TransformBegin()
+			pdf.TransformRotate(45, cx, cy)
+			pdf.Text(cx-textW/2, cy, text)
+			pdf.TransformEnd()
+		}
+
+		w.font("B", w.theme.Sizes.Small)
+		w.textColor(w.theme.Palette.Muted)
+		stamp := fmt.Sprintf("%s: %s", tr(w.lang, "Status"), label)
+		pdf.Text(pageW-pdf.GetStringWidth(stamp)-5, 6, stamp)
+		w.textColor(w.theme.Palette.Text)
+	})
+}
+
+// section writes a titled block of body text, which may use the Markdown
+// subset of parseRichText, followed by its snippets.
+func (w *pdfWriter) section(toc *pdfTOC, title, content string, snippets []Snippet) {
+	toc.mark(title)
+	w.font("B", w.theme.Sizes.Section)
+	w.cell(0, 10, title, "", 0, "", false, 0)
+	w.pdf.Ln(10)
+	w.font("", w.theme.Sizes.Body)
+	w.richText(w.lh(7), "", content)
+	for _, s := range snippets {
+		w.pdf.Ln(3)
+		w.snippet(s)
+	}
+	w.pdf.Ln(10)
+}
+
+// band writes a centered section title on a full-width band of the theme's
+// band color.
+func (w *pdfWriter) band(title string) {
+	w.font("B", w.theme.Sizes.Section)
+	w.fillColor(w.theme.Palette.Band)
+	w.textColor(w.theme.Palette.Text)
+	w.cell(0, 10, title, "", 1, "C", true, 0)
+}
+
+// references writes the numbered References appendix. Entries with a
+// URL become link annotations, so they can be clicked in any PDF viewer.
+func (w *pdfWriter) references(toc *pdfTOC, refs []reference) {
+	pdf := w.pdf
+	sizes, palette := w.theme.Sizes, w.theme.Palette
+	pdf.AddPage()
+	toc.mark(tr(w.lang, "References & Links"))
+	w.band(tr(w.lang, "References & Links"))
+	pdf.Ln(6)
+
+	left, _, _, _ := pdf.GetMargins()
+	const numW = 12
+	for _, ref := range refs {
+		y := pdf.GetY()
+		w.font("B", sizes.Body)
+		w.textColor(palette.Text)
+		w.cell(numW, 6, ref.Cite(), "", 0, "L", false, 0)
+
+		// Write() wraps at the right margin but restarts each line at the left
+		// margin, so indent the margin to keep wrapped lines under the label.
+		pdf.SetLeftMargin(left + numW)
+		pdf.SetXY(left+numW, y)
+		w.font("", sizes.Body)
+		if ref.URL == "" {
+			w.write(w.lh(6), ref.Label, "")
+		} else {
+			w.textColor(palette.Accent)
+			w.write(w.lh(6), ref.Label, ref.URL)
+			if ref.Label != ref.URL {
+				pdf.Ln(w.lh(5))
+				w.font("", sizes.Small)
+				w.textColor(palette.Muted)
+				w.write(w.lh(5), ref.URL, ref.URL)
+			}
+		}
+		pdf.SetLeftMargin(left)
+		pdf.Ln(8)
+	}
+	w.textColor(palette.Text)
+}
+
+// actionCards is the default CAPA layout: one block per action with
+// its priority, status and overdue state as colored badges.
+func (w *pdfWriter) actionCards(actions []Action, now time.Time) {
+	pdf := w.pdf
+	sizes, palette := w.theme.Sizes, w.theme.Palette
+	left, _, _, _ := pdf.GetMargins()
+	pdf.SetLineWidth(0.3)
+
+	for i, action := range actions {
+		badges := badgesFor(action, w.lang, now)
+
+		// Cabeçalho da ação
+		w.font("B", sizes.Subsection)
+		w.textColor(palette.Accent)
+		w.multiCell(0, 6, fmt.Sprintf("%s %d: %s", tr(w.lang, "Action"), i+1, action.Action), "L")
+		w.textColor(palette.Text)
+
+		// Prioridade e status lado a lado, como badges
+		if badges.Priority != nil || badges.Status != nil {
+			pdf.Ln(1)
+			y := pdf.GetY()
+			pdf.SetX(left)
+			if badges.Priority != nil {
+				w.labeledBadge(tr(w.lang, "Priority"), *badges.Priority)
+				pdf.SetX(pdf.GetX() + 6)
+			}
+			if badges.Status != nil {
+				w.labeledBadge(tr(w.lang, "Status"), *badges.Status)
+			}
+			pdf.SetXY(left, y+7)
+		}
+
+		// Metadados
+		w.font("", sizes.Body)
+		w.cell(0, 6, fmt.Sprintf("%s: %s", tr(w.lang, "Owner"), action.Owner), "", 1, "L", false, 0)
+		due := fmt.Sprintf("%s: %s", tr(w.lang, "Due Date"), formatDate(action.Due, w.lang))
+		if badges.Overdue != nil {
+			w.cell(w.stringWidth(due)+3, 6, due, "", 0, "L", false, 0)
+			w.badge(*badges.Overdue)
+			pdf.Ln(6)
+		} else {
+			w.cell(0, 6, due, "", 1, "L", false, 0)
+		}
+		pdf.Ln(3)
+
+		// Linha divisória entre ações
+		w.divider(5)
+		pdf.Ln(5)
+	}
+	pdf.Ln(5)
+}
+
+// labeledBadge writes "label:" followed by the badge, on the current line.
+func (w *pdfWriter) labeledBadge(label string, b badge) {
+	w.font("", w.theme.Sizes.Body)
+	w.textColor(w.theme.Palette.Text)
+	text := label + ":"
+	w.cell(w.stringWidth(text)+2, 6, text, "", 0, "L", false, 0)
+	w.badge(b)
+}
+
+// badge draws b as a rounded, filled label at the current position and
+// moves the cursor past it.
+func (w *pdfWriter) badge(b badge) {
+	pdf := w.pdf
+	c := badgeColors[b.Kind]
+	w.font("B", w.theme.Sizes.Small)
+	width := w.stringWidth(b.Text) + 4
+	x, y := pdf.GetX(), pdf.GetY()
+	pdf.SetFillColor(c.R, c.G, c.B)
+	pdf.RoundedRect(x, y+0.75, width, 4.5, 1.2, "1234", "F")
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetXY(x, y)
+	w.cell(width, 6, b.Text, "", 0, "C", false, 0)
+	w.textColor(w.theme.Palette.Text)
+	w.font("", w.theme.Sizes.Body)
+}
+
+// actionsTable is the tabular CAPA layout. The header is repeated at
+// the top of every page the table spans, and badge-worthy cells are shaded.
+func (w *pdfWriter) actionsTable(actions []Action, now time.Time) {
+	w.font("", w.theme.Sizes.Body)
+	if len(actions) == 0 {
+		w.multiCell(0, w.lh(7), tr(w.lang, "No actions recorded."), "")
+		return
+	}
+	usableW := w.textWidth()
+	ratios := []float64{0.40, 0.18, 0.12, 0.15, 0.15} // Action, Owner, Priority, Due, Status
+	widths := make([]float64, len(ratios))
+	for i, r := range ratios {
+		widths[i] = r * usableW
+	}
+	header := []string{tr(w.lang, "Action"), tr(w.lang, "Owner"), tr(w.lang, "Priority"), tr(w.lang, "Due"), tr(w.lang, "Status")}
+	w.drawColor(w.theme.Palette.Rule)
+	w.pdf.SetLineWidth(0.3)
+	w.tableHeader(header, widths)
+	for _, a := range actions {
+		badges := badgesFor(a, w.lang, now)
+		cells := []string{a.Action, a.Owner, a.Priority, formatDate(a.Due, w.lang), a.Status}
+		kinds := make([]string, len(cells))
+		if badges.Priority != nil && badges.Priority.Kind != badgeNeutral {
+			kinds[2] = badges.Priority.Kind
+		}
+		if badges.Overdue != nil {
+			kinds[3] = badgeOverdue
+		}
+		if badges.Status != nil && badges.Status.Kind != badgeNeutral {
+			kinds[4] = badges.Status.Kind
+		}
+		w.tableRow(header, cells, kinds, widths)
+	}
+}
+
+func (w *pdfWriter) tableHeader(header []string, widths []float64) {
+	pdf := w.pdf
+	w.font("B", w.theme.Sizes.Body)
+	h := 8.0
+	x := pdf.GetX()
+	y := pdf.GetY()
+	_, pageH := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if y+h > pageH-bottom {
+		pdf.AddPage()
+		x = pdf.GetX()
+		y = pdf.GetY()
+	}
+	w.fillColor(w.theme.Palette.Primary)
+	pdf.SetTextColor(255, 255, 255)
+	for i, text := range header {
+		pdf.Rect(x, y, widths[i], h, "DF")
+		pdf.SetXY(x, y)
+		w.cell(widths[i], h, text, "", 0, "C", false, 0)
+		x += widths[i]
+	}
+	w.textColor(w.theme.Palette.Text)
+	pdf.Ln(h)
+	w.font("", w.theme.Sizes.Body)
+}
+
+// tableRow writes one row, starting a new page (and repeating header)
+// when it does not fit. kinds[i] shades cell i with that badge color.
+func (w *pdfWriter) tableRow(header, cells, kinds []string, widths []float64) {
+	pdf := w.pdf
+	lineH := w.lh(6)
+	maxLines := 1
+	for i, txt := range cells {
+		lines := pdf.SplitLines([]byte(txt), widths[i]-2) // padding 1mm de cada lado
+		if len(lines) > maxLines {
+			maxLines = len(lines)
+		}
+	}
+	rowH := float64(maxLines)*lineH + 2
+
+	y := pdf.GetY()
+	_, pageH := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if y+rowH > pageH-bottom {
+		pdf.AddPage()
+		w.tableHeader(header, widths)
+	}
+
+	startX := pdf.GetX()
+	startY := pdf.GetY()
+	for i, txt := range cells {
+		style := "D"
+		if kinds[i] != "" {
+			c := badgeColors[kinds[i]]
+			pdf.SetFillColor(c.R, c.G, c.B)
+			pdf.SetTextColor(255, 255, 255)
+			w.font("B", w.theme.Sizes.Body)
+			style = "DF"
+		}
+		pdf.Rect(startX, startY, widths[i], rowH, style)
+		pdf.SetXY(startX+1, startY+1)
+		w.multiCell(widths[i]-2, lineH, txt, "L")
+		w.textColor(w.theme.Palette.Text)
+		w.font("", w.theme.Sizes.Body)
+		startX += widths[i]
+		pdf.SetXY(startX, startY)
+	}
+	pdf.Ln(rowH)
+}
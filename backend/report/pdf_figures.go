@@ -0,0 +1,72 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// figures draws the figures of a timeline entry in rows of the grid, each
+// image scaled to its share of the text width with its caption centered
+// under it. A row moves to the next page whole, so images are never cut and
+// captions stay with their images.
+func (w *pdfWriter) figures(figures []figure, lang string) {
+	pdf := w.pdf
+	style, size := w.style, w.size
+	left, _, _, _ := pdf.GetMargins()
+	_, pageH := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	width := w.textWidth()
+	margin := pdf.GetCellMargin()
+	pdf.SetCellMargin(0)
+	w.font("I", w.theme.Sizes.Small)
+	w.textColor(w.theme.Palette.Muted)
+	lineH := w.lh(4)
+
+	for _, row := range figureRows(figures) {
+		type placed struct {
+			figure
+			x, w, h, captionH float64
+		}
+		var cells []placed
+		rowH := 0.0
+		x := left
+		for _, f := range row {
+			cellW := figureWidth(f.fraction(), width)
+			captionH := 1 + float64(len(pdf.SplitText(f.label(lang), cellW)))*lineH
+			// A tall image is shrunk to fit on a page with its caption.
+			imgW := cellW
+			imgH := imgW * float64(f.H) / float64(f.W)
+			if maxH := w.textHeight() - captionH - figureGap; imgH > maxH {
+				imgW, imgH = imgW*maxH/imgH, maxH
+			}
+			cells = append(cells, placed{f, x, imgW, imgH, captionH})
+			rowH = math.Max(rowH, imgH+captionH)
+			x += cellW + figureGap
+		}
+		if pdf.GetY()+rowH > pageH-bottom {
+			pdf.AddPage()
+		}
+
+		top := pdf.GetY()
+		for _, c := range cells {
+			cellW := figureWidth(c.fraction(), width)
+			name := fmt.Sprintf("figure-%d", c.Number)
+			opts := gofpdf.ImageOptions{ImageType: strings.ToUpper(strings.TrimPrefix(imageExtension(c.MimeType), "."))}
+			pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(c.Data))
+			pdf.ImageOptions(name, c.x+(cellW-c.w)/2, top, c.w, c.h, false, opts, 0, "")
+
+			// Legenda numerada
+			pdf.SetXY(c.x, top+c.h+1)
+			w.multiCell(cellW, lineH, c.label(lang), "C")
+		}
+		pdf.SetXY(left, top+rowH+figureGap)
+	}
+
+	w.textColor(w.theme.Palette.Text)
+	pdf.SetCellMargin(margin)
+	w.font(style, size)
+}
@@ -0,0 +1,211 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// textRun is a stretch of text set in a single font of the writer's chain.
+type textRun struct {
+	font int
+	text string
+}
+
+// font selects the style and size for the text that follows, in the first
+// family of the fallback chain.
+func (w *pdfWriter) font(style string, size float64) {
+	w.style, w.size, w.code = style, size, false
+	w.use(0)
+}
+
+// codeFont is font for code, in the first family of the mono chain.
+func (w *pdfWriter) codeFont(style string, size float64) {
+	w.style, w.size, w.code = style, size, true
+	w.use(0)
+}
+
+// keepFont runs draw and then goes back to the font in use before it.
+// Headers and footers need it: gofpdf draws them in the middle of whatever
+// broke the page, and restores its own font but not the one tracked here.
+func (w *pdfWriter) keepFont(draw func()) {
+	style, size, code := w.style, w.size, w.code
+	draw()
+	w.style, w.size, w.code = style, size, code
+	if size > 0 {
+		w.use(0)
+	}
+}
+
+// chain is the fallback chain in use: the mono one after codeFont.
+func (w *pdfWriter) chain() []*fontFamily {
+	if w.code {
+		return w.mono
+	}
+	return w.fonts
+}
+
+// use switches to family i of the chain in the current style, registering
+// it with gofpdf the first time. Only the fonts a report actually uses end up
+// embedded in it.
+func (w *pdfWriter) use(i int) {
+	id := "font" + strconv.Itoa(i)
+	if w.code {
+		id = "mono" + strconv.Itoa(i)
+	}
+	key := id + w.style
+	if !w.registered[key] {
+		if w.registered == nil {
+			w.registered = map[string]bool{}
+		}
+		w.pdf.AddUTF8FontFromBytes(id, w.style, w.chain()[i].face(w.style).data)
+		w.registered[key] = true
+	}
+	w.pdf.SetFont(id, w.style, w.size)
+}
+
+// runs splits s into runs by the first family of the chain with a glyph for
+// each character. Spaces stay in the run they are in; characters no family
+// has are left in the primary font.
+func (w *pdfWriter) runs(s string) []textRun {
+	var runs []textRun
+	var b strings.Builder
+	cur := 0
+	for _, r := range s {
+		font := cur
+		if !unicode.IsSpace(r) {
+			font = w.fontFor(r)
+		}
+		if font != cur && b.Len() > 0 {
+			runs = append(runs, textRun{cur, b.String()})
+			b.Reset()
+		}
+		cur = font
+		b.WriteRune(r)
+	}
+	if b.Len() > 0 {
+		runs = append(runs, textRun{cur, b.String()})
+	}
+	return runs
+}
+
+func (w *pdfWriter) fontFor(r rune) int {
+	for i, f := range w.chain() {
+		if f.face(w.style).has(r) {
+			return i
+		}
+	}
+	return 0
+}
+
+// primaryOnly reports whether the primary font covers all of runs.
+func primaryOnly(runs []textRun) bool {
+	return len(runs) == 0 || (len(runs) == 1 && runs[0].font == 0)
+}
+
+// stringWidth is GetStringWidth across fonts.
+func (w *pdfWriter) stringWidth(s string) float64 {
+	var width float64
+	for _, run := range w.runs(s) {
+		w.use(run.font)
+		width += w.pdf.GetStringWidth(run.text)
+	}
+	w.use(0)
+	return width
+}
+
+// cell is CellFormat with font fallback.
+func (w *pdfWriter) cell(width, h float64, text, border string, ln int, align string, fill bool, link int) {
+	pdf := w.pdf
+	runs := w.runs(text)
+	if primaryOnly(runs) {
+		pdf.CellFormat(width, h, text, border, ln, align, fill, link, "")
+		return
+	}
+
+	left, _, right, _ := pdf.GetMargins()
+	pageW, _ := pdf.GetPageSize()
+	if width == 0 {
+		width = pageW - right - pdf.GetX()
+	}
+	// The empty cell draws the border and fill, and breaks the page if needed.
+	pdf.CellFormat(width, h, "", border, 0, "", fill, link, "")
+	x, y := pdf.GetX()-width, pdf.GetY()
+
+	textW := w.stringWidth(text)
+	margin := pdf.GetCellMargin()
+	tx := x + margin
+	switch {
+	case strings.Contains(align, "C"):
+		tx = x + (width-textW)/2
+	case strings.Contains(align, "R"):
+		tx = x + width - margin - textW
+	}
+	pdf.SetCellMargin(0)
+	for _, run := range runs {
+		w.use(run.font)
+		runW := pdf.GetStringWidth(run.text)
+		pdf.SetXY(tx, y)
+		pdf.CellFormat(runW, h, run.text, "", 0, "L", false, link, "")
+		tx += runW
+	}
+	pdf.SetCellMargin(margin)
+	w.use(0)
+
+	switch ln {
+	case 0:
+		pdf.SetXY(x+width, y)
+	case 1:
+		pdf.SetXY(left, y+h)
+	default:
+		pdf.SetXY(x, y+h)
+	}
+}
+
+// multiCell is MultiCell with font fallback. Text the primary font covers is
+// laid out by MultiCell as usual; anything else flows run by run with Write,
+// left aligned, between margins moved to the cell's edges.
+func (w *pdfWriter) multiCell(width, h float64, text, align string) {
+	pdf := w.pdf
+	runs := w.runs(text)
+	if primaryOnly(runs) {
+		pdf.MultiCell(width, h, text, "", align, false)
+		return
+	}
+
+	left, _, right, _ := pdf.GetMargins()
+	pageW, _ := pdf.GetPageSize()
+	x := pdf.GetX()
+	if width == 0 {
+		width = pageW - right - x
+	}
+	if align == "C" && !strings.Contains(text, "\n") && w.stringWidth(text) <= width {
+		w.cell(width, h, text, "", 2, "C", false, 0)
+		pdf.SetX(left)
+		return
+	}
+
+	pdf.SetLeftMargin(x)
+	pdf.SetRightMargin(pageW - x - width)
+	for _, run := range runs {
+		w.use(run.font)
+		pdf.Write(h, run.text)
+	}
+	pdf.SetLeftMargin(left)
+	pdf.SetRightMargin(right)
+	w.use(0)
+	pdf.Ln(h)
+}
+
+// write is Write, or WriteLinkString when url is set, with font fallback.
+func (w *pdfWriter) write(h float64, text, url string) {
+	for _, run := range w.runs(text) {
+		w.use(run.font)
+		if url == "" {
+			w.pdf.Write(h, run.text)
+		} else {
+			w.pdf.WriteLinkString(h, run.text, url)
+		}
+	}
+	w.use(0)
+}
@@ -0,0 +1,116 @@
+package report
+
+// metricChart draws c across the text width: its title, the incident bands
+// behind the plot, value gridlines, dashed milestone lines, one line per
+// series, the time ticks under the plot and the legend. The chart moves to
+// the next page whole rather than splitting.
+func (w *pdfWriter) metricChart(c metricChart) {
+	pdf := w.pdf
+	palette := w.theme.Palette
+	style, size := w.style, w.size
+	left, _, _, _ := pdf.GetMargins()
+	width := w.textWidth()
+	margin := pdf.GetCellMargin()
+	pdf.SetCellMargin(0)
+
+	w.font("", w.theme.Sizes.Small)
+	var gutter float64
+	for _, t := range c.YTicks {
+		if tw := w.stringWidth(t.Label); tw > gutter {
+			gutter = tw
+		}
+	}
+	gutter += 2
+	legend, legendH := w.layoutLegend(c.Legend, width)
+	height := metricTitle + metricPlot + metricXLabel + legendH
+	_, pageH := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if pdf.GetY()+height > pageH-bottom {
+		pdf.AddPage()
+	}
+	top := pdf.GetY()
+	plotX, plotY, plotW := left+gutter, top+metricTitle, width-gutter
+	x := func(at float64) float64 { return plotX + at*plotW }
+	y := func(at float64) float64 { return plotY + (1-at)*metricPlot }
+
+	// Título
+	w.font("B", w.theme.Sizes.Body)
+	w.textColor(palette.Text)
+	pdf.SetXY(left, top)
+	w.cell(width, metricTitle-1, c.Title, "", 0, "L", false, 0)
+
+	// Faixas de impacto e mitigação
+	w.font("", w.theme.Sizes.Small)
+	for _, b := range c.Bands {
+		w.fillColor(b.Fill)
+		pdf.Rect(x(b.From), plotY, x(b.To)-x(b.From), metricPlot, "F")
+	}
+
+	// Grade e valores
+	pdf.SetLineWidth(0.1)
+	w.drawColor(palette.Rule)
+	w.textColor(palette.Muted)
+	for _, t := range c.YTicks {
+		ty := y(t.At)
+		pdf.Line(plotX, ty, plotX+plotW, ty)
+		pdf.SetXY(left, ty-2)
+		w.cell(gutter-1, 4, t.Label, "", 0, "R", false, 0)
+	}
+	pdf.SetLineWidth(0.3)
+	pdf.Line(plotX, plotY, plotX, plotY+metricPlot)
+	pdf.Line(plotX, plotY+metricPlot, plotX+plotW, plotY+metricPlot)
+	for _, t := range c.XTicks {
+		tx := x(t.At)
+		pdf.Line(tx, plotY+metricPlot, tx, plotY+metricPlot+1.5)
+		tw := w.stringWidth(t.Label)
+		pdf.SetXY(tx-tw/2, plotY+metricPlot+2)
+		w.cell(tw, 4, t.Label, "", 0, "C", false, 0)
+	}
+
+	// Marcos do incidente
+	pdf.SetLineWidth(0.2)
+	w.drawColor(palette.Muted)
+	pdf.SetDashPattern([]float64{1, 1}, 0)
+	for _, m := range c.Marks {
+		pdf.Line(x(m.At), plotY, x(m.At), plotY+metricPlot)
+	}
+	pdf.SetDashPattern([]float64{}, 0)
+	for _, m := range c.Marks {
+		mw := w.stringWidth(m.Label)
+		mx := x(m.At) + 0.8
+		if m.At > chartFlipLeft {
+			mx = x(m.At) - 0.8 - mw
+		}
+		pdf.SetXY(mx, plotY+0.5+float64(m.Level)*metricMark)
+		w.cell(mw, metricMark, m.Label, "", 0, "L", false, 0)
+	}
+
+	// Séries
+	pdf.SetLineWidth(metricLine)
+	pdf.SetLineCapStyle("round")
+	pdf.SetLineJoinStyle("round")
+	for _, l := range c.Lines {
+		w.drawColor(l.Color)
+		w.fillColor(l.Color)
+		if len(l.Points) == 1 {
+			p := l.Points[0]
+			pdf.Circle(x(p.X), y(p.Y), metricLine, "F")
+			continue
+		}
+		for i := 1; i < len(l.Points); i++ {
+			a, b := l.Points[i-1], l.Points[i]
+			pdf.Line(x(a.X), y(a.Y), x(b.X), y(b.Y))
+		}
+	}
+	pdf.SetLineCapStyle("butt")
+	pdf.SetLineJoinStyle("miter")
+
+	w.legend(legend, left, plotY+metricPlot+metricXLabel)
+
+	pdf.SetLineWidth(0.3)
+	w.drawColor(palette.Rule)
+	w.textColor(palette.Text)
+	pdf.SetCellMargin(margin)
+	pdf.SetXY(left, top+height)
+	w.font(style, size)
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+)
+
+// listIndent is how far each list level is indented, in millimeters.
+const listIndent = 6
+
+// richText writes a narrative field, laying out the Markdown subset read by
+// parseRichText at the current font size. h is the line height of body text.
+// lead, when set, starts the first paragraph, as "Notes:" does in the
+// timeline.
+func (w *pdfWriter) richText(h float64, lead, text string) {
+	pdf := w.pdf
+	size := w.size
+	left, _, _, _ := pdf.GetMargins()
+	blocks := withLead(lead, parseRichText(text))
+
+	for i, b := range blocks {
+		if i > 0 {
+			pdf.Ln(h / 3)
+		}
+		pdf.SetX(left)
+		switch b.Kind {
+		case blockHeading:
+			// Headings sit below the section title, so h1 is only a little larger than the body.
+			hs := size + float64(max(0, 4-b.Level))
+			w.font("B", hs)
+			w.spans(h, b.Spans)
+			pdf.Ln(h)
+
+		case blockBullet, blockNumbered:
+			marker := "•"
+			if b.Kind == blockNumbered {
+				marker = strconv.Itoa(b.Number) + "."
+			}
+			x := left + float64(b.Level)*listIndent
+			w.font("", size)
+			pdf.SetX(x)
+			w.cell(listIndent, h, marker, "", 0, "L", false, 0)
+			// Wrapped lines of the item line up with its first line.
+			pdf.SetLeftMargin(x + listIndent)
+			w.spans(h, b.Spans)
+			pdf.Ln(h)
+			pdf.SetLeftMargin(left)
+
+		case blockCode:
+			w.snippet(Snippet{Lang: b.Lang, Code: b.Code})
+
+		default:
+			w.font("", size)
+			w.spans(h, b.Spans)
+			pdf.Ln(h)
+		}
+		pdf.SetX(left)
+	}
+	w.font("", size)
+}
+
+// spans writes inline text with Write, switching fonts for bold, italic and
+// code, and coloring links. The style of the current font is the base
+// style, so a heading stays bold.
+func (w *pdfWriter) spans(h float64, spans []richSpan) {
+	base, size := w.style, w.size
+	for _, s := range spans {
+		style := base
+		if s.Bold && !strings.Contains(style, "B") {
+			style += "B"
+		}
+		if s.Italic {
+			style += "I"
+		}
+		if s.Code {
+			w.codeFont(style, size*0.9)
+		} else {
+			w.font(style, size)
+		}
+		if s.URL != "" {
+			w.textColor(w.theme.Palette.Accent)
+		}
+		w.write(h, s.Text, s.URL)
+		if s.URL != "" {
+			w.textColor(w.theme.Palette.Text)
+		}
+	}
+	w.font(base, size)
+}
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+)
+
+// wrapMarker starts the continuation rows of a snippet line too long for
+// the box.
+const wrapMarker = "↪"
+
+// snippet writes a snippet in the mono font on a shaded box across the text
+// width: its title above, an optional line number gutter, and diff lines on
+// their own colors. Long lines wrap anywhere, as logs have no good break
+// points, and each continuation row starts with wrapMarker.
+func (w *pdfWriter) snippet(s Snippet) {
+	pdf := w.pdf
+	palette := w.theme.Palette
+	style, size := w.style, w.size
+	left, _, _, _ := pdf.GetMargins()
+	margin := pdf.GetCellMargin()
+	pdf.SetCellMargin(1)
+
+	if s.Title != "" {
+		w.font("B", w.theme.Sizes.Small)
+		w.textColor(palette.Muted)
+		pdf.SetX(left)
+		w.cell(0, w.lh(5), s.Title, "", 1, "L", false, 0)
+	}
+
+	lines := s.lines()
+	w.codeFont("", w.theme.Sizes.Small)
+	h := w.lh(4)
+	charW := pdf.GetStringWidth("0")
+	var gutterW float64
+	if s.LineNumbers {
+		gutterW = float64(len(strconv.Itoa(len(lines))))*charW + 3
+	}
+	markW := charW + 2
+	textW := w.textWidth() - gutterW - markW
+
+	padding := func() {
+		w.fillColor(codeShade)
+		pdf.SetX(left)
+		pdf.CellFormat(0, 1.5, "", "", 1, "", true, 0, "")
+	}
+	padding()
+	for _, line := range lines {
+		bg, fg := codeShade, palette.Text
+		switch line.Kind {
+		case lineAdded, lineRemoved:
+			bg, fg = snippetLineColors[line.Kind][0], snippetLineColors[line.Kind][1]
+		case lineHunk:
+			fg = palette.Accent
+		case lineHeader:
+			fg = palette.Muted
+		}
+		for i, row := range w.wrapRows(line.Text, textW-2) {
+			pdf.SetX(left)
+			if s.LineNumbers {
+				num := ""
+				if i == 0 {
+					num = strconv.Itoa(line.Number)
+				}
+				w.fillColor(codeShade)
+				w.textColor(palette.Muted)
+				w.cell(gutterW, h, num, "", 0, "R", true, 0)
+			}
+			mark := ""
+			if i > 0 {
+				mark = wrapMarker
+			}
+			w.fillColor(bg)
+			w.textColor(palette.Muted)
+			w.cell(markW, h, mark, "", 0, "C", true, 0)
+			w.textColor(fg)
+			w.cell(textW, h, row, "", 1, "L", true, 0)
+		}
+	}
+	padding()
+
+	w.textColor(palette.Text)
+	pdf.SetCellMargin(margin)
+	pdf.SetX(left)
+	w.font(style, size)
+}
+
+// wrapRows splits text into rows no wider than width in the current font.
+// An empty line is one empty row.
+func (w *pdfWriter) wrapRows(text string, width float64) []string {
+	if w.stringWidth(text) <= width {
+		return []string{text}
+	}
+	var rows []string
+	var b strings.Builder
+	widths := map[rune]float64{}
+	var cur float64
+	for _, r := range text {
+		rw, ok := widths[r]
+		if !ok {
+			rw = w.stringWidth(string(r))
+			widths[r] = rw
+		}
+		if cur+rw > width && b.Len() > 0 {
+			rows = append(rows, b.String())
+			b.Reset()
+			cur = 0
+		}
+		b.WriteRune(r)
+		cur += rw
+	}
+	return append(rows, b.String())
+}
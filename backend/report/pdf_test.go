@@ -0,0 +1,160 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPDFRenderer(t *testing.T) {
+	data := PostmortemData{
+		Title:      "Checkout API Failure",
+		Date:       "2025-10-18",
+		Severity:   "SEV-2",
+		StartTime:  "02:22",
+		EndTime:    "03:34",
+		Milestones: Milestones{Detected: "02:30", Acknowledged: "02:35", Mitigated: "03:10"},
+		Summary:    "Degradation observed in Checkout APIs.",
+		Timeline:   []TimelineEntry{{ID: "t1", Time: "02:22", Actor: "SRE", Notes: "Alert fired", Images: []Image{{Src: tinyPNG}}}},
+		Actions:    []Action{{Action: "Add TTL test", Owner: "Bob", Priority: "P1", Due: "2025-11-01", Status: "Open"}},
+		Lessons:    Lessons{Good: "Fast rollback"},
+		References: "Grafana | https://grafana.example.com/d/checkout\nRedis TTL notes",
+		Branding:   Branding{Logo: tinyPNG, Header: tinyPNG, Footer: tinyPNG},
+		Lang:       "pt",
+		Status:     StatusDraft,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, PDFRenderer{}.Render(context.Background(), data, &buf))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	assert.Contains(t, buf.String(), "/URI (https://grafana.example.com/d/checkout)")
+}
+
+func TestPDFRendererCAPATable(t *testing.T) {
+	actions := make([]Action, 40) // enough rows to span pages and repeat the header
+	for i := range actions {
+		actions[i] = Action{Action: "Add TTL test", Owner: "Bob", Priority: "P1", Due: "2020-01-01", Status: "Blocked"}
+	}
+	data := PostmortemData{
+		Title:    "Checkout API Failure",
+		Severity: "SEV-2",
+		Actions:  actions,
+		Options:  Options{CAPALayout: CAPALayoutTable},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, PDFRenderer{}.Render(context.Background(), data, &buf))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
+
+func TestPDFRendererHonorsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	err := PDFRenderer{}.Render(ctx, PostmortemData{Title: "x"}, &buf)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, buf.Len())
+}
+
+// drawPDF calls draw on a blank, uncompressed A4 page set in the default
+// theme with a 10 pt body font, and returns the PDF. Drawing must not fail,
+// and must leave the body font as it found it.
+func drawPDF(t *testing.T, draw func(w *pdfWriter)) string {
+	t.Helper()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	w := newPDFWriter(pdf, DefaultTheme(), "en", DefaultFonts())
+	w.font("", 10)
+	draw(w)
+	require.NoError(t, pdf.Error())
+	assert.Equal(t, 10.0, w.size, "the body font is restored")
+
+	var buf bytes.Buffer
+	require.NoError(t, pdf.Output(&buf))
+	return buf.String()
+}
+
+func TestPDFTableOfContents(t *testing.T) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage() // cover
+
+	toc := &pdfTOC{pdfWriter: newPDFWriter(pdf, DefaultTheme(), "pt", DefaultFonts())}
+	toc.reserve(2)
+	pdf.AddPage()
+	toc.mark("Visão Geral")
+	pdf.SetY(270) // too close to the bottom for a heading
+	toc.mark("Linha do Tempo")
+	toc.draw()
+
+	require.NoError(t, pdf.Error())
+	assert.Equal(t, 2, toc.page)
+	assert.Equal(t, 3, toc.entries[0].page)
+	assert.Equal(t, 4, toc.entries[1].page)
+	assert.Equal(t, 4, pdf.PageNo(), "drawing the TOC must not leave the cursor on its page")
+
+	var buf bytes.Buffer
+	require.NoError(t, pdf.Output(&buf))
+	assert.Contains(t, buf.String(), "/Outlines")
+}
+
+func TestPDFTableOfContentsContinues(t *testing.T) {
+	pdf := gofpdf.New("L", "mm", "A5", "")
+	pdf.SetCompression(false)
+	pdf.SetAutoPageBreak(true, 30) // room for a footer
+	pdf.AddPage()                  // cover
+
+	titles := make([]string, 30)
+	toc := &pdfTOC{pdfWriter: newPDFWriter(pdf, DefaultTheme(), "en", DefaultFonts())}
+	toc.reserve(len(titles))
+	require.Greater(t, toc.pages, 1)
+	pdf.AddPage()
+	for i := range titles {
+		titles[i] = fmt.Sprintf("Section %02d", i+1)
+		toc.mark(titles[i])
+	}
+	toc.draw()
+	require.NoError(t, pdf.Error())
+	assert.Equal(t, 1+toc.pages+1, toc.entries[0].page, "the body starts after the reserved pages")
+
+	var buf bytes.Buffer
+	require.NoError(t, pdf.Output(&buf))
+	// Each row links its title and its page number; every link must sit
+	// above the bottom margin (30 mm is 85 pt).
+	rects := regexp.MustCompile(`/Rect \[[\d.]+ [\d.]+ [\d.]+ ([\d.]+)\] /Border \[0 0 0\] /Dest`).FindAllStringSubmatch(buf.String(), -1)
+	assert.Len(t, rects, 2*len(titles), "every section is listed")
+	for _, r := range rects {
+		bottom, err := strconv.ParseFloat(r[1], 64)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, bottom, 85.0, "rows stay clear of the footer")
+	}
+}
+
+func TestTOCLength(t *testing.T) {
+	data := metricData()
+	data.Summary = "Checkout failed."
+	data.Impact = "Orders were lost."
+	data.RootCause = "An expired certificate."
+	data.Detection = "Alerts."
+	data.Response = "Rotated the certificate."
+	data.Comm = "Status page."
+	data.Actions = []Action{{Action: "Monitor expiry", Owner: "Ana"}}
+	data.Lessons = Lessons{Improve: "Automate rotation"}
+	data.References = "Runbook | https://runbook.example.com"
+	data.Options.Page = PageOptions{Size: "A5", Orientation: OrientationLandscape}
+	require.Equal(t, 14, tocLength(data), "every section the body can have")
+
+	var buf bytes.Buffer
+	require.NoError(t, PDFRenderer{}.Render(context.Background(), data, &buf))
+	// Each row of the table of contents links its title and its page number.
+	assert.Equal(t, 2*14, strings.Count(buf.String(), " null]>>"), "every section is listed")
+}
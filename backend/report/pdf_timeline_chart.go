@@ -0,0 +1,120 @@
+package report
+
+// chartLegendRow is the height of a row of the chart's legend.
+const chartLegendRow = 5.0
+
+// timelineChart draws c across the text width: the impact and mitigation
+// bands behind the axis, a marker on a stem for each entry with its time at
+// the top, the axis ticks below, and the legend under them. The chart moves
+// to the next page whole rather than splitting.
+func (w *pdfWriter) timelineChart(c timelineChart) {
+	pdf := w.pdf
+	palette := w.theme.Palette
+	style, size := w.style, w.size
+	small := w.theme.Sizes.Small
+	left, _, _, _ := pdf.GetMargins()
+	width := w.textWidth()
+	margin := pdf.GetCellMargin()
+	pdf.SetCellMargin(0)
+	w.font("", small)
+
+	// The legend is laid out first, as it decides the chart's height.
+	legend, legendH := w.layoutLegend(c.Legend, width)
+	height := chartHeight + 2 + legendH
+	_, pageH := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if pdf.GetY()+height > pageH-bottom {
+		pdf.AddPage()
+	}
+	top := pdf.GetY()
+	axis := top + chartAxis
+	x := func(at float64) float64 { return left + at*width }
+
+	// Faixas de impacto e mitigação
+	for _, b := range c.Bands {
+		bx, bw := x(b.From), x(b.To)-x(b.From)
+		w.fillColor(b.Fill)
+		pdf.Rect(bx, top+chartBandTop, bw, chartAxis-chartBandTop, "F")
+		w.textColor(palette.Muted)
+		pdf.SetXY(bx, top)
+		w.cell(bw, chartBandTop-0.5, b.Label, "", 0, "L", false, 0)
+	}
+
+	// Eixo e marcas de tempo
+	w.drawColor(palette.Rule)
+	pdf.SetLineWidth(0.4)
+	pdf.Line(left, axis, left+width, axis)
+	pdf.SetLineWidth(0.2)
+	w.textColor(palette.Muted)
+	for _, t := range c.Ticks {
+		tx := x(t.At)
+		pdf.Line(tx, axis, tx, axis+1.5)
+		tw := w.stringWidth(t.Label)
+		pdf.SetXY(tx-tw/2, axis+2)
+		w.cell(tw, 4, t.Label, "", 0, "C", false, 0)
+	}
+
+	// Eventos: haste, marcador e horário
+	w.textColor(palette.Text)
+	for _, e := range c.Events {
+		ex := x(e.At)
+		stemTop := axis - chartStem - float64(e.Level)*chartStagger
+		w.drawColor(e.Color)
+		pdf.Line(ex, axis, ex, stemTop)
+		w.fillColor(e.Color)
+		pdf.Circle(ex, axis, chartMarker, "F")
+		lw := w.stringWidth(e.Label)
+		lx := ex + 0.8
+		if e.At > chartFlipLeft {
+			lx = ex - 0.8 - lw
+		}
+		pdf.SetXY(lx, stemTop-2)
+		w.cell(lw, 4, e.Label, "", 0, "L", false, 0)
+	}
+
+	w.legend(legend, left, top+chartHeight+2)
+
+	pdf.SetLineWidth(0.3)
+	w.drawColor(palette.Rule)
+	pdf.SetCellMargin(margin)
+	pdf.SetXY(left, top+height)
+	w.font(style, size)
+}
+
+// legendItem is a legend entry placed relative to the legend's top left.
+type legendItem struct {
+	chartLegend
+	x, y, w float64
+}
+
+// layoutLegend places the entries in rows no wider than width, in the
+// current font, and returns them with the legend's height.
+func (w *pdfWriter) layoutLegend(entries []chartLegend, width float64) ([]legendItem, float64) {
+	var items []legendItem
+	lx, ly := 0.0, 0.0
+	for _, l := range entries {
+		lw := 2*chartMarker + 1.5 + w.stringWidth(l.Label)
+		if lx > 0 && lx+lw > width {
+			lx, ly = 0, ly+chartLegendRow
+		}
+		items = append(items, legendItem{l, lx, ly, lw})
+		lx += lw + 5
+	}
+	if len(items) == 0 {
+		return nil, 0
+	}
+	return items, ly + chartLegendRow
+}
+
+// legend draws items from layoutLegend, each a colored dot and its label.
+func (w *pdfWriter) legend(items []legendItem, x, y float64) {
+	pdf := w.pdf
+	w.textColor(w.theme.Palette.Text)
+	for _, l := range items {
+		lx, ly := x+l.x, y+l.y
+		w.fillColor(l.Color)
+		pdf.Circle(lx+chartMarker, ly+chartLegendRow/2, chartMarker, "F")
+		pdf.SetXY(lx+2*chartMarker+1.5, ly)
+		w.cell(l.w-2*chartMarker-1.5, chartLegendRow, l.Label, "", 0, "L", false, 0)
+	}
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+)
+
+// tocEntry is a heading listed in the table of contents.
+type tocEntry struct {
+	title string
+	page  int
+	link  int // internal link created with AddLink
+}
+
+// Table of contents geometry, in millimeters.
+const (
+	tocTitleH  = 20.0 // the title and the space below it, on the first page only
+	tocRowH    = 8.0
+	tocPageNoW = 15.0
+)
+
+// pdfTOC collects the document headings while the body is laid out, then
+// fills the table of contents pages reserved after the cover. Page numbers
+// are only known once the body is done, hence the deferred drawing.
+type pdfTOC struct {
+	*pdfWriter
+	page    int // the first reserved page
+	pages   int
+	entries []tocEntry
+}
+
+// reserve adds enough (still blank) pages to list n entries.
+func (t *pdfTOC) reserve(n int) {
+	_, top, _, bottom := t.pdf.GetMargins()
+	_, pageH := t.pdf.GetPageSize()
+	perPage := int((pageH - top - bottom) / tocRowH)
+	first := int((pageH - top - bottom - tocTitleH) / tocRowH)
+	t.pages = 1
+	if n > first && perPage > 0 {
+		t.pages += (n - first + perPage - 1) / perPage
+	}
+	for i := 0; i < t.pages; i++ {
+		t.pdf.AddPage()
+		if i == 0 {
+			t.page = t.pdf.PageNo()
+			t.pdf.Bookmark(tr(t.lang, "Table of Contents"), 0, -1)
+		}
+	}
+}
+
+// tocLength is the number of headings the PDF body marks for data, so the
+// table of contents can reserve its pages before the body is laid out. It
+// follows the conditions of PDFRenderer.Render.
+func tocLength(data PostmortemData) int {
+	n := 3 // overview, details and technical problems
+	for _, s := range []struct {
+		text     string
+		snippets []Snippet
+	}{
+		{data.Summary, data.Snippets.Summary},
+		{data.Impact, data.Snippets.Impact},
+		{data.RootCause, data.Snippets.RootCause},
+		{data.Detection, data.Snippets.Detection},
+		{data.Response, data.Snippets.Response},
+		{data.Comm, data.Snippets.Comm},
+	} {
+		if hasContent(s.text, s.snippets) {
+			n++
+		}
+	}
+	for _, present := range []bool{
+		len(data.Timeline) > 0,
+		len(metricCharts(data)) > 0,
+		len(data.Actions) > 0,
+		data.Lessons.Good != "" || data.Lessons.Improve != "",
+		len(parseReferences(data.References)) > 0,
+	} {
+		if present {
+			n++
+		}
+	}
+	return n
+}
+
+// mark registers a heading about to be written at the current position: it
+// becomes a TOC entry, a link target and a top-level bookmark. A heading that
+// would be left alone at the bottom of a page is moved to the next one first.
+func (t *pdfTOC) mark(title string) {
+	if title == "" {
+		return
+	}
+	_, pageH := t.pdf.GetPageSize()
+	_, _, _, bottom := t.pdf.GetMargins()
+	if t.pdf.GetY()+25 > pageH-bottom {
+		t.pdf.AddPage()
+	}
+	link := t.pdf.AddLink()
+	t.pdf.SetLink(link, -1, -1)
+	t.pdf.Bookmark(title, 0, -1)
+	t.entries = append(t.entries, tocEntry{title: title, page: t.pdf.PageNo(), link: link})
+}
+
+// draw writes the entries on the reserved pages, each one linking to its
+// heading.
+func (t *pdfTOC) draw() {
+	if t.page == 0 {
+		return
+	}
+	pdf := t.pdf
+	last := pdf.PageNo()
+	pdf.SetPage(t.page)
+	defer pdf.SetPage(last)
+
+	// The pages are already laid out; never let a long list spill onto a new
+	// one. The bottom margin is read first, as turning breaks off clears it.
+	left, top, right, bottom := pdf.GetMargins()
+	autoBreak, breakMargin := pdf.GetAutoPageBreak()
+	pdf.SetAutoPageBreak(false, 0)
+	defer pdf.SetAutoPageBreak(autoBreak, breakMargin)
+
+	pageW, pageH := pdf.GetPageSize()
+	width := pageW - left - right
+
+	pdf.SetXY(left, top)
+	t.textColor(t.theme.Palette.Text)
+	t.font("B", t.theme.Sizes.Heading)
+	t.cell(0, 12, tr(t.lang, "Table of Contents"), "", 1, "C", false, 0)
+	pdf.Ln(8)
+
+	t.font("", t.theme.Sizes.Subsection)
+	dotW := pdf.GetStringWidth(".")
+	page := t.page
+	for _, e := range t.entries {
+		if pdf.GetY()+tocRowH > pageH-bottom && page < t.page+t.pages-1 {
+			page++
+			pdf.SetPage(page)
+			pdf.SetXY(left, top)
+		}
+		title := e.title
+		if free := width - tocPageNoW - t.stringWidth(title+" ") - 2; free > dotW {
+			title += " " + strings.Repeat(".", int(free/dotW))
+		}
+		t.cell(width-tocPageNoW, tocRowH, title, "", 0, "L", false, e.link)
+		t.cell(tocPageNoW, tocRowH, strconv.Itoa(e.page), "", 1, "R", false, e.link)
+	}
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// reference is one numbered entry of the References appendix. URL is empty
+// for plain-text references and for links with a scheme we do not render.
+type reference struct {
+	Number int
+	Label  string
+	URL    string
+}
+
+// Cite is how the body refers to the entry, e.g. "[1]".
+func (r reference) Cite() string {
+	return "[" + strconv.Itoa(r.Number) + "]"
+}
+
+// parseReferences reads PostmortemData.References: one reference per line,
+// either a bare URL, free text, or "label | URL". Blank lines are skipped and
+// the rest are numbered from 1 in order, so the body can cite them as [n].
+func parseReferences(text string) []reference {
+	var refs []reference
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "- "), "* "))
+		if line == "" {
+			continue
+		}
+		ref := reference{Number: len(refs) + 1, Label: line}
+		if i := strings.LastIndex(line, "|"); i >= 0 {
+			label, link := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+			if u := referenceURL(link); u != "" {
+				ref.Label, ref.URL = label, u
+				if label == "" {
+					ref.Label = u
+				}
+			}
+		} else {
+			ref.URL = referenceURL(line)
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// referenceURL returns s when it is an absolute http(s) or mailto URL, the
+// only kinds of link we put in a report.
+func referenceURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || strings.ContainsAny(s, " \t") {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return ""
+		}
+		return s
+	case "mailto":
+		if u.Opaque == "" {
+			return ""
+		}
+		return s
+	}
+	return ""
+}
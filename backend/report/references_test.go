@@ -0,0 +1,26 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReferences(t *testing.T) {
+	refs := parseReferences(`
+Grafana dashboard | https://grafana.example.com/d/checkout
+- https://status.example.com/incidents/42
+
+Redis TTL RFC (internal wiki)
+Evil | javascript:alert(1)
+On-call | mailto:oncall@example.com
+`)
+	assert.Equal(t, []reference{
+		{Number: 1, Label: "Grafana dashboard", URL: "https://grafana.example.com/d/checkout"},
+		{Number: 2, Label: "https://status.example.com/incidents/42", URL: "https://status.example.com/incidents/42"},
+		{Number: 3, Label: "Redis TTL RFC (internal wiki)"},
+		{Number: 4, Label: "Evil | javascript:alert(1)"},
+		{Number: 5, Label: "On-call", URL: "mailto:oncall@example.com"},
+	}, refs)
+	assert.Equal(t, "[3]", refs[2].Cite())
+}
@@ -0,0 +1,165 @@
+// Package report turns postmortem data into documents. Every output format
+// implements Renderer, so the HTTP server, the CLI and other Go tooling all
+// share the same layouts.
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Renderer writes a postmortem in one output format.
+type Renderer interface {
+	// Render writes the document for data to w.
+	Render(ctx context.Context, data PostmortemData, w io.Writer) error
+	// MediaType is the MIME type of the rendered document.
+	MediaType() string
+	// Extension is the file extension, including the dot, of the rendered document.
+	Extension() string
+}
+
+// Output format names accepted by New.
+const (
+	FormatPDF      = "pdf"
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+	FormatDOCX     = "docx"
+)
+
+// ErrUnknownFormat is returned by New for format names it does not know.
+var ErrUnknownFormat = errors.New("unknown report format")
+
+// New returns a renderer with default settings for the named format.
+func New(format string) (Renderer, error) {
+	switch format {
+	case FormatPDF:
+		return PDFRenderer{}, nil
+	case FormatMarkdown:
+		return MarkdownRenderer{}, nil
+	case FormatHTML:
+		return HTMLRenderer{}, nil
+	case FormatDOCX:
+		return DOCXRenderer{}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Postmortem lifecycle states, as carried in PostmortemData.Status.
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusApproved  = "approved"
+	StatusPublished = "published"
+)
+
+type TimelineEntry struct {
+	ID       string    `json:"id"`
+	Time     string    `json:"time"`
+	Actor    string    `json:"actor"`
+	Notes    string    `json:"notes"`
+	Images   []Image   `json:"images"`
+	Snippets []Snippet `json:"snippets,omitempty"`
+}
+
+type Action struct {
+	Action   string `json:"action"`
+	Owner    string `json:"owner"`
+	Priority string `json:"priority"`
+	Due      string `json:"due"`
+	Status   string `json:"status"`
+}
+
+type Lessons struct {
+	Good    string `json:"good"`
+	Improve string `json:"improve"`
+}
+
+type Branding struct {
+	Logo   string `json:"logo"`   // data URL or asset reference
+	Header string `json:"header"` // data URL or asset reference
+	Footer string `json:"footer"` // data URL or asset reference
+}
+
+// FooterOptions add identifying text to the footer of paged formats (PDF, DOCX).
+type FooterOptions struct {
+	Classification string `json:"classification,omitempty"` // e.g. "Internal" or "Confidential"
+	IncidentID     string `json:"incidentId,omitempty"`
+	Timestamp      bool   `json:"timestamp,omitempty"` // print when the report was generated
+}
+
+// Options control how a postmortem is rendered rather than what it says.
+type Options struct {
+	Footer     FooterOptions `json:"footer"`
+	CAPALayout string        `json:"capaLayout,omitempty"` // CAPALayoutCards (default) or CAPALayoutTable
+	Theme      ThemeRef      `json:"theme"`                // a theme name or an inline theme; see ThemeStore
+	Page       PageOptions   `json:"page"`
+	// TimelineChart colors the timeline chart's markers by TimelineChartActor
+	// (default) or TimelineChartPhase, or turns it off with TimelineChartOff.
+	TimelineChart string `json:"timelineChart,omitempty"`
+	// Images sets the resolution and JPEG quality images are embedded at.
+	Images ImageOptions `json:"images"`
+}
+
+type PostmortemData struct {
+	Title      string          `json:"title"`
+	Date       string          `json:"date"`
+	Severity   string          `json:"severity"`
+	Owners     string          `json:"owners"`
+	Creator    string          `json:"creator"`
+	Duration   string          `json:"duration"`
+	Affected   string          `json:"affected"`
+	Summary    string          `json:"summary"`
+	Impact     string          `json:"impact"`
+	RootCause  string          `json:"rootCause"`
+	Detection  string          `json:"detection"`
+	Response   string          `json:"response"`
+	Comm       string          `json:"comm"`
+	Snippets   SectionSnippets `json:"snippets"` // logs, output and diffs printed after each section's text
+	Timeline   []TimelineEntry `json:"timeline"`
+	Charts     []MetricChart   `json:"charts,omitempty"` // time series drawn as line charts after the timeline
+	Actions    []Action        `json:"actions"`
+	Lessons    Lessons         `json:"lessons"`
+	References string          `json:"references"`
+	Branding   Branding        `json:"branding"`
+	Lang       string          `json:"lang"`
+	StartTime  string          `json:"startTime"`          // HH:MM on Date
+	EndTime    string          `json:"endTime"`            // HH:MM; earlier than StartTime means the next day
+	StartAt    string          `json:"startAt,omitempty"`  // full start datetime, e.g. 2025-10-18T02:22; overrides Date/StartTime
+	EndAt      string          `json:"endAt,omitempty"`    // full end datetime; overrides EndTime
+	Timezone   string          `json:"timezone,omitempty"` // IANA zone for the times above, e.g. America/Sao_Paulo
+	Milestones Milestones      `json:"milestones"`         // lifecycle points the TTD/TTA/TTM/TTR metrics come from
+	Status     string          `json:"status,omitempty"`   // lifecycle state, stamped on every page
+	Options    Options         `json:"options"`
+}
+
+// statusLabel is the localized, human-readable name of a status.
+func statusLabel(status, lang string) string {
+	switch status {
+	case StatusDraft:
+		return tr(lang, "Draft")
+	case StatusInReview:
+		return tr(lang, "In Review")
+	case StatusApproved:
+		return tr(lang, "Approved")
+	case StatusPublished:
+		return tr(lang, "Published")
+	}
+	return status
+}
+
+// status is the lifecycle state of the report. A report without one is a
+// draft: nobody has approved it, so it must not go out looking final.
+func (data PostmortemData) status() string {
+	if data.Status == "" {
+		return StatusDraft
+	}
+	return data.Status
+}
+
+// needsWatermark reports whether pages should carry a diagonal status
+// watermark, which is the case until the postmortem is approved.
+func needsWatermark(status string) bool {
+	return status == StatusDraft || status == StatusInReview
+}
@@ -0,0 +1,354 @@
+package report
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The narrative fields (summary, impact, root cause, detection, response,
+// communications, lessons and timeline notes) accept a small, safe subset of
+// Markdown: ATX headings, bullet and numbered lists, fenced code blocks, and
+// inline bold, italic, code and links. Anything else, raw HTML included, is
+// kept as literal text. Single line breaks inside a paragraph are kept, as
+// the plain-text fields always did.
+
+// codeShade is the background of code blocks in every format.
+const codeShade Color = "#F3F4F6"
+
+// blockKind is the kind of a richBlock.
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockBullet
+	blockNumbered
+	blockCode
+)
+
+// richBlock is one block of a narrative field.
+type richBlock struct {
+	Kind   blockKind
+	Level  int        // heading level 1-6, or list nesting depth from 0
+	Number int        // number of a numbered list item
+	Spans  []richSpan // the text of everything but code blocks
+	Code   string     // the text of a code block, without the fences
+	Lang   string     // info string of a code block, e.g. "sql"
+}
+
+// richSpan is a run of inline text with the same formatting.
+type richSpan struct {
+	Text   string
+	Bold   bool
+	Italic bool
+	Code   bool
+	URL    string // only http(s) and mailto, as accepted by referenceURL
+}
+
+var (
+	headingLine  = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	bulletLine   = regexp.MustCompile(`^([ \t]*)[-*+][ \t]+(.*)$`)
+	numberedLine = regexp.MustCompile(`^([ \t]*)(\d{1,9})[.)][ \t]+(.*)$`)
+	fenceLine    = regexp.MustCompile("^[ \t]*(```+|~~~+)[ \t]*([^`]*?)[ \t]*$")
+)
+
+// parseRichText splits a narrative field into blocks.
+func parseRichText(text string) []richBlock {
+	var blocks []richBlock
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, richBlock{Kind: blockParagraph, Spans: parseInline(strings.Join(para, "\n"))})
+			para = nil
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if m := fenceLine.FindStringSubmatch(line); m != nil {
+			flush()
+			fence := m[1]
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) && strings.Trim(strings.TrimSpace(lines[i]), fence[:1]) == "" {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			// An unclosed fence runs to the end of the field.
+			blocks = append(blocks, richBlock{Kind: blockCode, Code: strings.Join(code, "\n"), Lang: m[2]})
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if m := headingLine.FindStringSubmatch(line); m != nil {
+			flush()
+			blocks = append(blocks, richBlock{Kind: blockHeading, Level: len(m[1]), Spans: parseInline(m[2])})
+			continue
+		}
+		if m := bulletLine.FindStringSubmatch(line); m != nil {
+			flush()
+			blocks = append(blocks, richBlock{Kind: blockBullet, Level: indentLevel(m[1]), Spans: parseInline(m[2])})
+			continue
+		}
+		if m := numberedLine.FindStringSubmatch(line); m != nil {
+			flush()
+			n, _ := strconv.Atoi(m[2])
+			blocks = append(blocks, richBlock{Kind: blockNumbered, Level: indentLevel(m[1]), Number: n, Spans: parseInline(m[3])})
+			continue
+		}
+		if len(para) == 0 {
+			if n := len(blocks); n > 0 && isListItem(blocks[n-1]) && startsIndented(line) {
+				// A lazy continuation line belongs to the list item above.
+				item := &blocks[n-1]
+				item.Spans = append(item.Spans, parseInline("\n"+strings.TrimSpace(line))...)
+				continue
+			}
+		}
+		para = append(para, strings.TrimRight(line, " \t"))
+	}
+	flush()
+	return blocks
+}
+
+// withLead puts lead at the start of the first paragraph of blocks, or in
+// a paragraph of its own when they start with something else.
+func withLead(lead string, blocks []richBlock) []richBlock {
+	if lead == "" {
+		return blocks
+	}
+	if len(blocks) > 0 && blocks[0].Kind == blockParagraph {
+		blocks[0].Spans = append([]richSpan{{Text: lead + " "}}, blocks[0].Spans...)
+		return blocks
+	}
+	return append([]richBlock{{Kind: blockParagraph, Spans: []richSpan{{Text: lead}}}}, blocks...)
+}
+
+// indentLevel is the list nesting depth of an indentation: every two
+// spaces, or a tab, is one level.
+func indentLevel(indent string) int {
+	width := 0
+	for _, r := range indent {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width / 2
+}
+
+func isListItem(b richBlock) bool {
+	return b.Kind == blockBullet || b.Kind == blockNumbered
+}
+
+func startsIndented(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// inlineEscapable are the characters a backslash makes literal.
+const inlineEscapable = "\\`*_[]()#+-.!>~|"
+
+// parseInline reads the inline formatting of one block. Delimiters without a
+// matching closer, and links whose URL we do not render, stay as text.
+func parseInline(s string) []richSpan {
+	p := inlineParser{}
+	p.parse(s, false)
+	p.flush()
+	return p.spans
+}
+
+type inlineParser struct {
+	spans        []richSpan
+	text         strings.Builder
+	bold, italic bool
+	url          string
+}
+
+func (p *inlineParser) flush() {
+	if p.text.Len() == 0 {
+		return
+	}
+	span := richSpan{Text: p.text.String(), Bold: p.bold, Italic: p.italic, URL: p.url}
+	p.text.Reset()
+	if n := len(p.spans); n > 0 {
+		if last := &p.spans[n-1]; !last.Code && last.Bold == span.Bold && last.Italic == span.Italic && last.URL == span.URL {
+			last.Text += span.Text
+			return
+		}
+	}
+	p.spans = append(p.spans, span)
+}
+
+func (p *inlineParser) parse(s string, inLink bool) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(inlineEscapable, s[i+1]) >= 0:
+			p.text.WriteByte(s[i+1])
+			i += 2
+
+		case c == '`':
+			n := runLength(s[i:], '`')
+			fence := s[i : i+n]
+			end := strings.Index(s[i+n:], fence)
+			if end < 0 {
+				p.text.WriteString(fence)
+				i += n
+				continue
+			}
+			p.flush()
+			code := s[i+n : i+n+end]
+			if t := strings.TrimSpace(code); t != "" {
+				code = t
+			}
+			p.spans = append(p.spans, richSpan{Text: code, Code: true, Bold: p.bold, Italic: p.italic, URL: p.url})
+			i += n + end + n
+
+		case c == '_' && i > 0 && isWordByte(s, i-1) && i+1 < len(s) && isWordByte(s, i+1):
+			p.text.WriteByte(c) // snake_case
+			i++
+
+		case (c == '*' || c == '_') && i+1 < len(s) && s[i+1] == c:
+			delim := s[i : i+2]
+			if p.bold || closes(s, i, delim) {
+				p.flush()
+				p.bold = !p.bold
+			} else {
+				p.text.WriteString(delim)
+			}
+			i += 2
+
+		case c == '*' || c == '_':
+			if p.italic || closes(s, i, s[i:i+1]) {
+				p.flush()
+				p.italic = !p.italic
+			} else {
+				p.text.WriteByte(c)
+			}
+			i++
+
+		case c == '[' && !inLink:
+			label, url, n := parseLink(s[i:])
+			if n == 0 {
+				p.text.WriteByte(c)
+				i++
+				continue
+			}
+			p.flush()
+			p.url = referenceURL(url)
+			p.parse(label, true)
+			p.flush()
+			p.url = ""
+			i += n
+
+		default:
+			p.text.WriteByte(c)
+			i++
+		}
+	}
+}
+
+// closes reports whether the emphasis delimiter at s[i:] opens a span that
+// is closed later on. Underscores inside words, as in snake_case names, and
+// delimiters followed by a space are not emphasis.
+func closes(s string, i int, delim string) bool {
+	after := i + len(delim)
+	if after >= len(s) || s[after] == ' ' || s[after] == '\t' || s[after] == '\n' {
+		return false
+	}
+	if delim[0] == '_' && i > 0 && isWordByte(s, i-1) {
+		return false
+	}
+	for j := after + 1; j <= len(s)-len(delim); j++ {
+		if s[j] == '\n' && j+1 < len(s) && s[j+1] == '\n' {
+			return false
+		}
+		if s[j:j+len(delim)] != delim || s[j-1] == ' ' || s[j-1] == '\\' {
+			continue
+		}
+		if len(delim) == 1 && j+1 < len(s) && s[j+1] == delim[0] {
+			j++ // part of a double delimiter
+			continue
+		}
+		if delim[0] == '_' && j+len(delim) < len(s) && isWordByte(s, j+len(delim)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// isWordByte reports whether the character starting or ending at s[i] is a
+// letter or digit.
+func isWordByte(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	if r == utf8.RuneError {
+		r, _ = utf8.DecodeLastRuneInString(s[:i+1])
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func runLength(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+// parseLink reads "[label](url)" at the start of s and returns its parts
+// and length, or n == 0 when s does not start with a link.
+func parseLink(s string) (label, url string, n int) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if i+1 >= len(s) || s[i+1] != '(' {
+				return "", "", 0
+			}
+			// The URL may hold balanced parentheses, as in Wikipedia links.
+			parens := 1
+			for j := i + 2; j < len(s) && s[j] != '\n'; j++ {
+				switch s[j] {
+				case '(':
+					parens++
+				case ')':
+					parens--
+				}
+				if parens == 0 {
+					url = strings.TrimSpace(s[i+2 : j])
+					url = strings.TrimSuffix(strings.TrimPrefix(url, "<"), ">")
+					return s[1:i], url, j + 1
+				}
+			}
+			return "", "", 0
+		case '\n':
+			return "", "", 0
+		}
+	}
+	return "", "", 0
+}
+
+// plainText is the text of spans without formatting.
+func plainText(spans []richSpan) string {
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const richNotes = "## Impact\n" +
+	"Checkout failed for **EU customers**, see [the dashboard](https://grafana.example.com/d/1).\n" +
+	"Raw <b>html</b> and [a trap](javascript:alert(1)) stay text.\n" +
+	"\n" +
+	"- rolled back `api-v2`\n" +
+	"  - cleared the _session_cache_ keys\n" +
+	"2. paged the DBA\n" +
+	"\n" +
+	"```sql\n" +
+	"SELECT * FROM orders WHERE id = 1;\n" +
+	"```"
+
+func TestParseRichText(t *testing.T) {
+	blocks := parseRichText(richNotes)
+	require.Len(t, blocks, 6)
+
+	assert.Equal(t, richBlock{Kind: blockHeading, Level: 2, Spans: []richSpan{{Text: "Impact"}}}, blocks[0])
+
+	assert.Equal(t, blockParagraph, blocks[1].Kind)
+	assert.Equal(t, []richSpan{
+		{Text: "Checkout failed for "},
+		{Text: "EU customers", Bold: true},
+		{Text: ", see "},
+		{Text: "the dashboard", URL: "https://grafana.example.com/d/1"},
+		{Text: ".\nRaw <b>html</b> and a trap stay text."},
+	}, blocks[1].Spans)
+
+	assert.Equal(t, richBlock{Kind: blockBullet, Spans: []richSpan{{Text: "rolled back "}, {Text: "api-v2", Code: true}}}, blocks[2])
+	assert.Equal(t, richBlock{Kind: blockBullet, Level: 1, Spans: []richSpan{{Text: "cleared the "}, {Text: "session_cache", Italic: true}, {Text: " keys"}}}, blocks[3])
+	assert.Equal(t, richBlock{Kind: blockNumbered, Number: 2, Spans: []richSpan{{Text: "paged the DBA"}}}, blocks[4])
+	assert.Equal(t, richBlock{Kind: blockCode, Lang: "sql", Code: "SELECT * FROM orders WHERE id = 1;"}, blocks[5])
+}
+
+func TestParseInlineLeavesPlainTextAlone(t *testing.T) {
+	for _, s := range []string{
+		"2 * 3 * 4 = 24",
+		"set max_conn_count to 10",
+		"**unclosed bold",
+		"a [bracket] and (parens)",
+		"`unclosed code",
+	} {
+		assert.Equal(t, []richSpan{{Text: s}}, parseInline(s), s)
+	}
+	assert.Equal(t, []richSpan{{Text: "*literal*"}}, parseInline(`\*literal\*`))
+}
+
+func TestRichHTML(t *testing.T) {
+	out := string(richHTML("Notes:", richNotes))
+
+	assert.Contains(t, out, "<p>Notes:</p><h4>Impact</h4>")
+	assert.Contains(t, out, `<strong>EU customers</strong>`)
+	assert.Contains(t, out, `<a href="https://grafana.example.com/d/1" rel="noopener noreferrer">the dashboard</a>`)
+	assert.Contains(t, out, ".<br>Raw &lt;b&gt;html&lt;/b&gt; and a trap stay text.")
+	assert.Contains(t, out, "<ul><li>rolled back <code>api-v2</code><ul><li>cleared the <em>session_cache</em> keys</li></ul></li></ul>")
+	assert.Contains(t, out, `<ol start="2"><li>paged the DBA</li></ol>`)
+	assert.Contains(t, out, `<figure class="snippet"><pre><span class="line">SELECT * FROM orders WHERE id = 1;</span></pre></figure>`)
+	assert.NotContains(t, out, "javascript")
+
+	assert.Equal(t, "<p>Notes: Rollback</p>", string(richHTML("Notes:", "Rollback")))
+}
+
+func TestRichTextRenderers(t *testing.T) {
+	data := PostmortemData{
+		Title:    "Checkout API Failure",
+		Summary:  richNotes,
+		Timeline: []TimelineEntry{{ID: "t1", Time: "02:22", Actor: "SRE", Notes: richNotes}},
+		Lessons:  Lessons{Good: "- fast **rollback**", Improve: "1. alert on `5xx`"},
+		Lang:     "en",
+	}
+
+	t.Run("pdf", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, PDFRenderer{}.Render(context.Background(), data, &buf))
+		assert.Contains(t, buf.String(), "/URI (https://grafana.example.com/d/1)")
+		assert.NotContains(t, buf.String(), "javascript")
+		assert.Contains(t, buf.String(), "/BaseFont /utf8mono0")
+	})
+
+	t.Run("docx", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, DOCXRenderer{}.Render(context.Background(), data, &buf))
+		doc := readDOCXPart(t, buf.Bytes(), "word/document.xml")
+		rels := readDOCXPart(t, buf.Bytes(), "word/_rels/document.xml.rels")
+		assert.Contains(t, doc, `<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">EU customers</w:t></w:r>`)
+		assert.Contains(t, doc, `<w:rFonts w:ascii="DejaVu Sans Mono" w:hAnsi="DejaVu Sans Mono" w:cs="DejaVu Sans Mono"/></w:rPr><w:t xml:space="preserve">api-v2</w:t>`)
+		assert.Contains(t, doc, `<w:shd w:val="clear" w:color="auto" w:fill="F3F4F6"/>`)
+		assert.Contains(t, rels, `Target="https://grafana.example.com/d/1" TargetMode="External"`)
+		assert.NotContains(t, rels, "javascript")
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		doc, _ := renderMarkdown(data, true)
+		assert.Contains(t, doc, "## Executive Summary\n\n#### Impact\n")
+		assert.Contains(t, doc, "### 02:22 | Actor: SRE\n\n##### Impact\n")
+		assert.Contains(t, doc, "```sql\nSELECT * FROM orders WHERE id = 1;\n```")
+		assert.Contains(t, doc, "Checkout failed for **EU customers**, see [the dashboard](<https://grafana.example.com/d/1>).\n")
+		assert.Contains(t, doc, "Raw &lt;b&gt;html&lt;/b&gt; and a trap stay text.")
+		assert.Contains(t, doc, "- rolled back `api-v2`\n    - cleared the *session\\_cache* keys\n2. paged the DBA\n")
+		assert.Contains(t, doc, "**What went well:**\n\n- fast **rollback**\n")
+		assert.NotContains(t, doc, "javascript")
+		assert.NotContains(t, doc, "<b>")
+	})
+}
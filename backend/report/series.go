@@ -0,0 +1,195 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricChart is a line chart of time series, such as an error rate or a
+// p99 latency, drawn instead of a dashboard screenshot. Series come from
+// Series, CSV, or both.
+type MetricChart struct {
+	Title  string         `json:"title"`
+	Unit   string         `json:"unit,omitempty"` // e.g. "%" or "ms", printed after the title
+	Series []MetricSeries `json:"series,omitempty"`
+	// CSV is a time column followed by one value column per series, with an
+	// optional header row naming them, as exported by Grafana.
+	CSV string `json:"csv,omitempty"`
+}
+
+// MetricSeries is one line of a chart.
+type MetricSeries struct {
+	Name   string        `json:"name"`
+	Points []MetricPoint `json:"points"`
+}
+
+// MetricPoint is a value at a time. Time is a datetime, an HH:MM read like
+// the milestones, or a Unix timestamp in seconds (or milliseconds). In JSON
+// a point is either {"time": …, "value": …} or a [time, value] pair, where
+// both may be strings or numbers, as in Prometheus query results.
+type MetricPoint struct {
+	Time  string  `json:"time"`
+	Value float64 `json:"value"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *MetricPoint) UnmarshalJSON(b []byte) error {
+	var raw struct{ Time, Value json.RawMessage }
+	var pair []json.RawMessage
+	if err := json.Unmarshal(b, &pair); err == nil {
+		if len(pair) != 2 {
+			return fmt.Errorf("a point must be a [time, value] pair, got %d values", len(pair))
+		}
+		raw.Time, raw.Value = pair[0], pair[1]
+	} else if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	t, err := jsonScalar(raw.Time)
+	if err != nil {
+		return fmt.Errorf("point time: %w", err)
+	}
+	v, err := jsonScalar(raw.Value)
+	if err != nil {
+		return fmt.Errorf("point value: %w", err)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return fmt.Errorf("point value %q is not a number", v)
+	}
+	*p = MetricPoint{Time: t, Value: value}
+	return nil
+}
+
+// jsonScalar is the value of a JSON string, or the literal text of a number.
+func jsonScalar(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return "", fmt.Errorf("expected a string or a number, got %s", raw)
+	}
+	return n.String(), nil
+}
+
+// allSeries is Series followed by the series read from CSV. Empty CSV
+// cells are left out, so a series may have gaps.
+func (c MetricChart) allSeries() ([]MetricSeries, error) {
+	if strings.TrimSpace(c.CSV) == "" {
+		return c.Series, nil
+	}
+	r := csv.NewReader(strings.NewReader(c.CSV))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return c.Series, nil
+	}
+
+	columns := len(rows[0])
+	if columns < 2 {
+		return nil, fmt.Errorf("expected a time column and at least one value column")
+	}
+	csvSeries := make([]MetricSeries, columns-1)
+	for i := range csvSeries {
+		csvSeries[i].Name = fmt.Sprintf("Series %d", len(c.Series)+i+1)
+	}
+	// A header row is one whose values are not numbers.
+	if header := rows[0]; !isNumber(header[1]) {
+		for i, name := range header[1:] {
+			if name = strings.TrimSpace(name); name != "" {
+				csvSeries[i].Name = name
+			}
+		}
+		rows = rows[1:]
+	}
+	for n, row := range rows {
+		if len(row) != columns {
+			return nil, fmt.Errorf("row %d has %d columns, expected %d", n+1, len(row), columns)
+		}
+		for i, cell := range row[1:] {
+			if cell = strings.TrimSpace(cell); cell == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %q is not a number", n+1, cell)
+			}
+			csvSeries[i].Points = append(csvSeries[i].Points, MetricPoint{Time: strings.TrimSpace(row[0]), Value: v})
+		}
+	}
+	return append(append([]MetricSeries(nil), c.Series...), csvSeries...), nil
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
+
+// seriesReader reads point times: Unix timestamps, or whatever clockReader
+// accepts. Each series is read with its own clock, so HH:MM times roll over
+// within a series only.
+type seriesReader struct {
+	data PostmortemData
+}
+
+// timedPoint is a point with its time resolved.
+type timedPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// read resolves the points of s and sorts them by time, so a series given
+// out of order still draws a line from left to right. bad lists the
+// indexes of the points whose time could not be read, which are left out,
+// as are NaN and infinite values.
+func (r seriesReader) read(s MetricSeries) (points []timedPoint, bad []int) {
+	clock := newClockReader(r.data)
+	loc := incidentLocation(r.data)
+	for i, p := range s.Points {
+		t, ok := unixTime(p.Time, loc)
+		if !ok {
+			t, ok = clock.read(p.Time)
+		}
+		if !ok {
+			bad = append(bad, i)
+			continue
+		}
+		if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+			continue
+		}
+		points = append(points, timedPoint{t, p.Value})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points, bad
+}
+
+// unixTime reads a Unix timestamp in seconds, with an optional fraction, or
+// in milliseconds when it has more than 11 integer digits.
+func unixTime(value string, loc *time.Location) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	secs, frac, _ := strings.Cut(value, ".")
+	if secs == "" || strings.Trim(secs, "0123456789") != "" || strings.Trim(frac, "0123456789") != "" {
+		return time.Time{}, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if len(secs) > 11 {
+		f /= 1000
+	}
+	whole, fraction := math.Modf(f)
+	return time.Unix(int64(whole), int64(fraction*1e9)).In(loc), true
+}
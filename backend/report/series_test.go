@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricPointJSON(t *testing.T) {
+	var s MetricSeries
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "5xx", "points": [
+		[1714604400, "0.5"],
+		["23:10", 12],
+		{"time": "2024-05-01T23:20:00Z", "value": 3.25}
+	]}`), &s))
+	assert.Equal(t, []MetricPoint{{"1714604400", 0.5}, {"23:10", 12}, {"2024-05-01T23:20:00Z", 3.25}}, s.Points)
+
+	var p MetricPoint
+	assert.Error(t, json.Unmarshal([]byte(`[1714604400]`), &p))
+	assert.Error(t, json.Unmarshal([]byte(`["23:10", "n/a"]`), &p))
+}
+
+func TestMetricChartCSV(t *testing.T) {
+	c := MetricChart{
+		Series: []MetricSeries{{Name: "inline", Points: []MetricPoint{{"23:00", 1}}}},
+		CSV:    "Time,api,db\n23:00,120,40\n23:30,2500,\n00:00,400,90\n",
+	}
+	series, err := c.allSeries()
+	require.NoError(t, err)
+	require.Len(t, series, 3)
+	assert.Equal(t, "inline", series[0].Name)
+	assert.Equal(t, MetricSeries{Name: "api", Points: []MetricPoint{{"23:00", 120}, {"23:30", 2500}, {"00:00", 400}}}, series[1])
+	assert.Equal(t, MetricSeries{Name: "db", Points: []MetricPoint{{"23:00", 40}, {"00:00", 90}}}, series[2], "empty cells are gaps")
+
+	series, err = MetricChart{CSV: "23:00,1\n23:05,2"}.allSeries()
+	require.NoError(t, err)
+	assert.Equal(t, []MetricSeries{{Name: "Series 1", Points: []MetricPoint{{"23:00", 1}, {"23:05", 2}}}}, series)
+
+	for _, bad := range []string{"23:00\n23:05", "time,v\n23:00,high", "time,a,b\n23:00,1"} {
+		_, err := MetricChart{CSV: bad}.allSeries()
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSeriesReader(t *testing.T) {
+	data := PostmortemData{Date: "2024-05-01", StartTime: "23:00", Timezone: "America/Sao_Paulo"}
+	points, bad := seriesReader{data}.read(MetricSeries{Points: []MetricPoint{
+		{"23:50", 1},
+		{"00:10", 2},
+		{"1714615200", 3},
+		{"1714615260000", 4},
+		{"soon", 5},
+	}})
+	assert.Equal(t, []int{4}, bad)
+	require.Len(t, points, 4)
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	// The Unix times are 23:00 and 23:01 in São Paulo, so they sort first.
+	assert.True(t, points[0].Time.Equal(time.Unix(1714615200, 0)))
+	assert.True(t, points[1].Time.Equal(time.Unix(1714615260, 0)), "milliseconds")
+	assert.Equal(t, time.Date(2024, 5, 1, 23, 50, 0, 0, loc), points[2].Time)
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 10, 0, 0, loc), points[3].Time, "HH:MM rolls over like the milestones")
+}
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"strings"
+)
+
+// Snippet is a block of logs, command output, configuration or a diff
+// attached to a timeline entry or a section. It is printed verbatim in the
+// theme's mono font.
+type Snippet struct {
+	Title       string `json:"title,omitempty"` // e.g. "kubectl get pods -n checkout"
+	Lang        string `json:"lang,omitempty"`  // "diff" (or "patch") colors added and removed lines
+	Code        string `json:"code"`
+	LineNumbers bool   `json:"lineNumbers,omitempty"`
+}
+
+// SectionSnippets are the snippets printed at the end of each narrative
+// section, after its text.
+type SectionSnippets struct {
+	Summary   []Snippet `json:"summary,omitempty"`
+	Impact    []Snippet `json:"impact,omitempty"`
+	RootCause []Snippet `json:"rootCause,omitempty"`
+	Detection []Snippet `json:"detection,omitempty"`
+	Response  []Snippet `json:"response,omitempty"`
+	Comm      []Snippet `json:"comm,omitempty"`
+}
+
+// Kinds of snippet lines. Only diffs have lines other than lineContext.
+const (
+	lineContext = ""
+	lineAdded   = "add"
+	lineRemoved = "del"
+	lineHunk    = "hunk" // "@@ -1,4 +1,5 @@"
+	lineHeader  = "meta" // "diff --git", "+++ b/file", "index …"
+)
+
+// Diff line colors, as background and text, in every format.
+var snippetLineColors = map[string][2]Color{
+	lineAdded:   {"#E6FFEC", "#116329"},
+	lineRemoved: {"#FFEBE9", "#82071E"},
+}
+
+// snippetLine is one source line of a snippet.
+type snippetLine struct {
+	Number int // from 1
+	Text   string
+	Kind   string
+}
+
+// isDiff reports whether the snippet's lines are colored as a diff.
+func (s Snippet) isDiff() bool {
+	switch strings.ToLower(strings.TrimSpace(s.Lang)) {
+	case "diff", "patch":
+		return true
+	}
+	return false
+}
+
+// lines splits the code into lines, with tabs expanded to four columns and
+// the trailing newline, if any, dropped.
+func (s Snippet) lines() []snippetLine {
+	code := strings.TrimRight(strings.ReplaceAll(s.Code, "\r\n", "\n"), "\n")
+	diff := s.isDiff()
+	var lines []snippetLine
+	for i, text := range strings.Split(code, "\n") {
+		line := snippetLine{Number: i + 1, Text: expandTabs(text)}
+		if diff {
+			line.Kind = diffLineKind(text)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func diffLineKind(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "),
+		strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+		return lineHeader
+	case strings.HasPrefix(line, "@@"):
+		return lineHunk
+	case strings.HasPrefix(line, "+"):
+		return lineAdded
+	case strings.HasPrefix(line, "-"):
+		return lineRemoved
+	}
+	return lineContext
+}
+
+// expandTabs replaces tabs with spaces up to the next multiple of four
+// columns, so indentation survives fonts and viewers that ignore tabs.
+func expandTabs(s string) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range s {
+		if r == '\t' {
+			n := 4 - col%4
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
+
+// hasContent reports whether a section has text or snippets to print.
+func hasContent(text string, snippets []Snippet) bool {
+	return strings.TrimSpace(text) != "" || len(snippets) > 0
+}
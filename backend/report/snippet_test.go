@@ -0,0 +1,153 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var diffSnippet = Snippet{
+	Title:       "git diff config/redis.yaml",
+	Lang:        "diff",
+	LineNumbers: true,
+	Code: "--- a/config/redis.yaml\n" +
+		"+++ b/config/redis.yaml\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		" cache:\n" +
+		"-\tttl: 0\n" +
+		"+\tttl: 300\n",
+}
+
+func TestSnippetLines(t *testing.T) {
+	assert.Equal(t, []snippetLine{
+		{1, "--- a/config/redis.yaml", lineHeader},
+		{2, "+++ b/config/redis.yaml", lineHeader},
+		{3, "@@ -1,3 +1,3 @@", lineHunk},
+		{4, " cache:", lineContext},
+		{5, "-   ttl: 0", lineRemoved},
+		{6, "+   ttl: 300", lineAdded},
+	}, diffSnippet.lines())
+
+	logs := Snippet{Code: "+ kubectl apply\n- not a diff"}
+	assert.Equal(t, []snippetLine{{1, "+ kubectl apply", lineContext}, {2, "- not a diff", lineContext}}, logs.lines())
+}
+
+func TestWrapRows(t *testing.T) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	w := newPDFWriter(pdf, DefaultTheme(), "en", DefaultFonts())
+	w.codeFont("", 8)
+	charW := pdf.GetStringWidth("0")
+
+	assert.Equal(t, []string{""}, w.wrapRows("", 10*charW))
+	assert.Equal(t, []string{"short"}, w.wrapRows("short", 10*charW))
+	assert.Equal(t, []string{"0123456789", "0123456789", "01"}, w.wrapRows(strings.Repeat("0123456789", 2)+"01", 10.5*charW))
+}
+
+func TestSnippetRenderers(t *testing.T) {
+	stack := Snippet{Title: "stack trace", Code: "panic: " + strings.Repeat("runtime error: invalid memory address ", 6)}
+	data := PostmortemData{
+		Title:    "Checkout API Failure",
+		Timeline: []TimelineEntry{{ID: "t1", Time: "02:22", Actor: "SRE", Snippets: []Snippet{stack}}},
+		Snippets: SectionSnippets{RootCause: []Snippet{diffSnippet}},
+		Lang:     "en",
+	}
+
+	t.Run("pdf", func(t *testing.T) {
+		out := drawPDF(t, func(w *pdfWriter) {
+			w.snippet(diffSnippet)
+			w.snippet(stack)
+		})
+		assert.Contains(t, out, "0.902 1.000 0.925 rg", "added lines are shaded green")
+		assert.Contains(t, out, "1.000 0.922 0.914 rg", "removed lines are shaded red")
+
+		var buf bytes.Buffer
+		require.NoError(t, PDFRenderer{}.Render(context.Background(), data, &buf))
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+		out := buf.String()
+		assert.Contains(t, out, "<h2>Root Cause</h2>")
+		assert.Contains(t, out, `<figcaption>git diff config/redis.yaml</figcaption>`)
+		assert.Contains(t, out, `<span class="line add"><span class="ln">6</span>&#43;   ttl: 300</span>`)
+		assert.Contains(t, out, `<figcaption>stack trace</figcaption><pre><span class="line">panic: `)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		doc, _ := renderMarkdown(data, true)
+		assert.Contains(t, doc, "## Root Cause\n\n**git diff config/redis.yaml**\n\n```diff\n--- a/config/redis.yaml\n")
+		assert.Contains(t, doc, "**stack trace**\n\n```\npanic: ")
+	})
+
+	t.Run("docx", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, DOCXRenderer{}.Render(context.Background(), data, &buf))
+		doc := readDOCXPart(t, buf.Bytes(), "word/document.xml")
+		assert.Contains(t, doc, `<w:color w:val="116329"/><w:sz w:val="16"/><w:shd w:val="clear" w:color="auto" w:fill="E6FFEC"/></w:rPr><w:t xml:space="preserve">+   ttl: 300</w:t>`)
+		assert.Contains(t, doc, `<w:t xml:space="preserve">6  </w:t>`)
+	})
+}
+
+// pdfContents inflates the page contents of a compressed PDF, in page order.
+func pdfContents(t *testing.T, b []byte) []string {
+	var pages []string
+	for _, m := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(b, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(r)
+		if err == nil && bytes.Contains(content, []byte(" Tf ET")) {
+			pages = append(pages, string(content))
+		}
+	}
+	require.NotEmpty(t, pages)
+	return pages
+}
+
+func TestSnippetKeepsMonoFontAcrossPages(t *testing.T) {
+	fontSelection := regexp.MustCompile(`BT /F(\w+) [\d.]+ Tf ET`)
+	out := drawPDF(t, func(w *pdfWriter) { w.codeFont("", 10) })
+	selections := fontSelection.FindAllStringSubmatch(out, -1)
+	mono := selections[len(selections)-1][1]
+
+	data := PostmortemData{
+		Title:    "Checkout API Failure",
+		Severity: "SEV-2",
+		Status:   StatusDraft, // the watermark and the footer both change fonts
+		Snippets: SectionSnippets{RootCause: []Snippet{{Code: strings.Repeat("ERROR redis: connection reset by peer\n", 150)}}},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, PDFRenderer{}.Render(context.Background(), data, &buf))
+
+	var continued string
+	seen := 0
+	for _, page := range pdfContents(t, buf.Bytes()) {
+		if strings.Contains(page, "/F"+mono+" ") {
+			if seen++; seen == 2 {
+				continued = page
+				break
+			}
+		}
+	}
+	require.NotEmpty(t, continued, "the snippet runs onto a second page")
+	fonts := map[string]int{}
+	for _, m := range fontSelection.FindAllStringSubmatch(continued, -1) {
+		fonts[m[1]]++
+	}
+	for font, n := range fonts {
+		if font != mono {
+			assert.Less(t, n, fonts[mono], "the rows after the page break stay in the mono font")
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Color is a "#RRGGBB" hex color.
+type Color string
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Valid reports whether c is a well-formed "#RRGGBB" color.
+func (c Color) Valid() bool {
+	return colorPattern.MatchString(string(c))
+}
+
+// RGB splits c into its components; invalid colors are black.
+func (c Color) RGB() (r, g, b int) {
+	if !c.Valid() {
+		return 0, 0, 0
+	}
+	v, _ := strconv.ParseUint(string(c[1:]), 16, 32)
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)
+}
+
+// hex is c without the leading "#", as WordprocessingML wants it.
+func (c Color) hex() string {
+	return strings.ToUpper(strings.TrimPrefix(string(c), "#"))
+}
+
+// Palette holds the colors a report is drawn with.
+type Palette struct {
+	Primary Color `json:"primary,omitempty" yaml:"primary,omitempty"` // overview labels and table headers
+	Accent  Color `json:"accent,omitempty" yaml:"accent,omitempty"`   // timeline and action titles, links
+	Text    Color `json:"text,omitempty" yaml:"text,omitempty"`
+	Muted   Color `json:"muted,omitempty" yaml:"muted,omitempty"` // secondary text: UTC times, footer, URLs
+	Rule    Color `json:"rule,omitempty" yaml:"rule,omitempty"`   // divider lines and cell borders
+	Band    Color `json:"band,omitempty" yaml:"band,omitempty"`   // background of the CAPA, Lessons and References headings
+}
+
+// ThemeFonts selects the typeface. In PDFs the families are looked up in the
+// renderer's FontLibrary; HTML and DOCX name them and leave it to the viewer.
+type ThemeFonts struct {
+	Family string `json:"family,omitempty" yaml:"family,omitempty"`
+	// Fallback families are tried, in order, for characters Family has no
+	// glyph for, such as CJK, Cyrillic or emoji.
+	Fallback []string `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+	// Mono is the family for inline code and code blocks.
+	Mono string `json:"mono,omitempty" yaml:"mono,omitempty"`
+}
+
+// FontSizes are in points.
+type FontSizes struct {
+	Title      float64 `json:"title,omitempty" yaml:"title,omitempty"`           // report title on the cover
+	Heading    float64 `json:"heading,omitempty" yaml:"heading,omitempty"`       // page headings such as Incident Overview
+	Section    float64 `json:"section,omitempty" yaml:"section,omitempty"`       // section titles
+	Subsection float64 `json:"subsection,omitempty" yaml:"subsection,omitempty"` // timeline events, actions, lessons
+	Body       float64 `json:"body,omitempty" yaml:"body,omitempty"`
+	Small      float64 `json:"small,omitempty" yaml:"small,omitempty"` // footer, badges, secondary lines
+}
+
+// Margins are in millimeters.
+type Margins struct {
+	Top    float64 `json:"top,omitempty" yaml:"top,omitempty"`
+	Right  float64 `json:"right,omitempty" yaml:"right,omitempty"`
+	Bottom float64 `json:"bottom,omitempty" yaml:"bottom,omitempty"`
+	Left   float64 `json:"left,omitempty" yaml:"left,omitempty"`
+}
+
+// Theme is the visual identity of a report. Zero fields take the value of
+// DefaultTheme, so a theme file only needs what differs from it.
+type Theme struct {
+	Name    string     `json:"name,omitempty" yaml:"name,omitempty"`
+	Palette Palette    `json:"palette" yaml:"palette"`
+	Fonts   ThemeFonts `json:"fonts" yaml:"fonts"`
+	Sizes   FontSizes  `json:"sizes" yaml:"sizes"`
+	Margins Margins    `json:"margins" yaml:"margins"`
+	// LineSpacing scales the spacing between lines of body text; 1 keeps
+	// each format's usual spacing.
+	LineSpacing float64 `json:"lineSpacing,omitempty" yaml:"lineSpacing,omitempty"`
+}
+
+// DefaultTheme is the look reports have always had.
+func DefaultTheme() Theme {
+	return Theme{
+		Name: "default",
+		Palette: Palette{
+			Primary: "#004B8D",
+			Accent:  "#004785",
+			Text:    "#000000",
+			Muted:   "#646464",
+			Rule:    "#A0A0A0",
+			Band:    "#FFFFFF",
+		},
+		Fonts:       ThemeFonts{Family: DefaultFontFamily, Mono: DefaultMonoFontFamily},
+		Sizes:       FontSizes{Title: 20, Heading: 18, Section: 14, Subsection: 11, Body: 10, Small: 8},
+		Margins:     Margins{Top: 30, Right: 15, Bottom: 15, Left: 15},
+		LineSpacing: 1,
+	}
+}
+
+// withDefaults fills the zero fields of t from DefaultTheme. Malformed
+// colors are replaced too, so renderers can use every color as-is.
+func (t Theme) withDefaults() Theme {
+	d := DefaultTheme()
+	color := func(c *Color, def Color) {
+		if !c.Valid() {
+			*c = def
+		}
+	}
+	number := func(n *float64, def float64) {
+		if *n <= 0 {
+			*n = def
+		}
+	}
+	color(&t.Palette.Primary, d.Palette.Primary)
+	color(&t.Palette.Accent, d.Palette.Accent)
+	color(&t.Palette.Text, d.Palette.Text)
+	color(&t.Palette.Muted, d.Palette.Muted)
+	color(&t.Palette.Rule, d.Palette.Rule)
+	color(&t.Palette.Band, d.Palette.Band)
+	if t.Fonts.Family == "" {
+		t.Fonts.Family = d.Fonts.Family
+	}
+	if t.Fonts.Mono == "" {
+		t.Fonts.Mono = d.Fonts.Mono
+	}
+	number(&t.Sizes.Title, d.Sizes.Title)
+	number(&t.Sizes.Heading, d.Sizes.Heading)
+	number(&t.Sizes.Section, d.Sizes.Section)
+	number(&t.Sizes.Subsection, d.Sizes.Subsection)
+	number(&t.Sizes.Body, d.Sizes.Body)
+	number(&t.Sizes.Small, d.Sizes.Small)
+	number(&t.Margins.Top, d.Margins.Top)
+	number(&t.Margins.Right, d.Margins.Right)
+	number(&t.Margins.Bottom, d.Margins.Bottom)
+	number(&t.Margins.Left, d.Margins.Left)
+	number(&t.LineSpacing, d.LineSpacing)
+	return t
+}
+
+// invalidColors lists the palette entries that are set but not "#RRGGBB".
+func (t Theme) invalidColors() []string {
+	p := t.Palette
+	var bad []string
+	for _, c := range []struct {
+		name  string
+		color Color
+	}{
+		{"primary", p.Primary}, {"accent", p.Accent}, {"text", p.Text},
+		{"muted", p.Muted}, {"rule", p.Rule}, {"band", p.Band},
+	} {
+		if c.color != "" && !c.color.Valid() {
+			bad = append(bad, c.name)
+		}
+	}
+	return bad
+}
+
+// ParseTheme reads a theme written in JSON or YAML.
+func ParseTheme(b []byte) (*Theme, error) {
+	var t Theme
+	// JSON is a subset of YAML, so one decoder reads both.
+	if err := yaml.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+	if bad := t.invalidColors(); len(bad) > 0 {
+		return nil, fmt.Errorf("theme colors must be #RRGGBB: %s", strings.Join(bad, ", "))
+	}
+	return &t, nil
+}
+
+// LoadTheme reads a theme file.
+func LoadTheme(path string) (*Theme, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseTheme(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return t, nil
+}
+
+// ThemeRef is how a postmortem picks its theme: by name, or inline. In JSON
+// it is either a string ("acme") or a theme object.
+type ThemeRef struct {
+	Name  string
+	Theme *Theme
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *ThemeRef) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*r = ThemeRef{}
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		*r = ThemeRef{}
+		return json.Unmarshal(b, &r.Name)
+	}
+	var t Theme
+	if err := json.Unmarshal(b, &t); err != nil {
+		return err
+	}
+	*r = ThemeRef{Name: t.Name, Theme: &t}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r ThemeRef) MarshalJSON() ([]byte, error) {
+	if r.Theme != nil {
+		return json.Marshal(r.Theme)
+	}
+	if r.Name == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(r.Name)
+}
+
+// theme is the theme to render with. Names that were never resolved by a
+// ThemeStore fall back to the default theme.
+func (r ThemeRef) theme() Theme {
+	if r.Theme == nil {
+		return DefaultTheme()
+	}
+	return r.Theme.withDefaults()
+}
+
+// ErrThemeNotFound is returned when a named theme has no file in the store.
+var ErrThemeNotFound = errors.New("theme not found")
+
+var themeNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ThemeStore loads named themes from <Dir>/<name>.json, .yaml or .yml.
+type ThemeStore struct {
+	Dir string
+}
+
+// Load reads the theme called name.
+func (s ThemeStore) Load(name string) (*Theme, error) {
+	if !themeNamePattern.MatchString(name) || s.Dir == "" {
+		return nil, fmt.Errorf("%w: %q", ErrThemeNotFound, name)
+	}
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		t, err := LoadTheme(filepath.Join(s.Dir, name+ext))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		return t, err
+	}
+	return nil, fmt.Errorf("%w: %q", ErrThemeNotFound, name)
+}
+
+// Resolve loads the theme data asks for so the renderers can use it. An
+// inline theme is used as given; a named one must exist. Without either, the
+// organization's theme (a file named after org) is used if there is one,
+// then the store's "default" theme, then DefaultTheme.
+func (s ThemeStore) Resolve(data *PostmortemData, org string) error {
+	ref := &data.Options.Theme
+	if ref.Theme != nil {
+		return nil
+	}
+	if ref.Name != "" {
+		t, err := s.Load(ref.Name)
+		if err != nil {
+			return err
+		}
+		ref.Theme = t
+		return nil
+	}
+	for _, name := range nonEmpty(org, "default") {
+		t, err := s.Load(name)
+		if errors.Is(err, ErrThemeNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		ref.Theme = t
+		return nil
+	}
+	return nil
+}
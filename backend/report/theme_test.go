@@ -0,0 +1,106 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTheme(t *testing.T) {
+	yamlTheme := []byte("palette:\n  primary: \"#112233\"\nsizes:\n  body: 11\nlineSpacing: 1.2\n")
+	th, err := ParseTheme(yamlTheme)
+	require.NoError(t, err)
+	full := th.withDefaults()
+	assert.Equal(t, Color("#112233"), full.Palette.Primary)
+	assert.Equal(t, DefaultTheme().Palette.Accent, full.Palette.Accent)
+	assert.Equal(t, 11.0, full.Sizes.Body)
+	assert.Equal(t, DefaultTheme().Margins, full.Margins)
+	assert.Equal(t, 1.2, full.LineSpacing)
+
+	th, err = ParseTheme([]byte(`{"fonts": {"family": "Inter"}, "margins": {"left": 25}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Inter", th.Fonts.Family)
+	assert.Equal(t, 25.0, th.withDefaults().Margins.Left)
+
+	_, err = ParseTheme([]byte(`{"palette": {"accent": "blue"}}`))
+	assert.ErrorContains(t, err, "accent")
+}
+
+func TestColorRGB(t *testing.T) {
+	r, g, b := Color("#004B8D").RGB()
+	assert.Equal(t, []int{0, 75, 141}, []int{r, g, b})
+	assert.Equal(t, "004B8D", Color("#004b8d").hex())
+	assert.False(t, Color("004B8D").Valid())
+}
+
+func TestThemeRefJSON(t *testing.T) {
+	var opts Options
+	require.NoError(t, json.Unmarshal([]byte(`{"theme": "acme"}`), &opts))
+	assert.Equal(t, ThemeRef{Name: "acme"}, opts.Theme)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"theme": {"name": "inline", "palette": {"primary": "#FF0000"}}}`), &opts))
+	require.NotNil(t, opts.Theme.Theme)
+	assert.Equal(t, "inline", opts.Theme.Name)
+	assert.Equal(t, Color("#FF0000"), opts.Theme.theme().Palette.Primary)
+
+	out, err := json.Marshal(ThemeRef{Name: "acme"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `"acme"`, string(out))
+
+	// An unresolved name renders with the default theme.
+	assert.Equal(t, DefaultTheme(), ThemeRef{Name: "acme"}.theme())
+}
+
+func TestThemeStoreResolve(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("acme.yaml", "palette:\n  primary: \"#AA0000\"\n")
+	write("globex.json", `{"palette": {"primary": "#00AA00"}}`)
+	write("default.yml", "palette:\n  primary: \"#0000AA\"\n")
+	store := ThemeStore{Dir: dir}
+
+	resolve := func(data PostmortemData, org string) Color {
+		require.NoError(t, store.Resolve(&data, org))
+		return data.Options.Theme.theme().Palette.Primary
+	}
+	named := PostmortemData{Options: Options{Theme: ThemeRef{Name: "acme"}}}
+	assert.Equal(t, Color("#AA0000"), resolve(named, "globex"))
+	assert.Equal(t, Color("#00AA00"), resolve(PostmortemData{}, "globex"))
+	assert.Equal(t, Color("#0000AA"), resolve(PostmortemData{}, "initech"))
+
+	inline := PostmortemData{Options: Options{Theme: ThemeRef{Theme: &Theme{Palette: Palette{Primary: "#123456"}}}}}
+	assert.Equal(t, Color("#123456"), resolve(inline, "acme"))
+
+	err := store.Resolve(&PostmortemData{Options: Options{Theme: ThemeRef{Name: "missing"}}}, "")
+	assert.ErrorIs(t, err, ErrThemeNotFound)
+	err = store.Resolve(&PostmortemData{Options: Options{Theme: ThemeRef{Name: "../acme"}}}, "")
+	assert.ErrorIs(t, err, ErrThemeNotFound)
+}
+
+func TestRenderWithTheme(t *testing.T) {
+	data := PostmortemData{
+		Title: "Themed",
+		Options: Options{Theme: ThemeRef{Theme: &Theme{
+			Palette: Palette{Primary: "#AB1234"},
+			Fonts:   ThemeFonts{Family: `Inter"; } body {`},
+			Margins: Margins{Left: 25, Right: 25},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+	assert.Contains(t, buf.String(), "--primary: #AB1234")
+	assert.Contains(t, buf.String(), `--font: "Inter  body", Verdana`)
+
+	buf.Reset()
+	require.NoError(t, PDFRenderer{}.Render(context.Background(), data, &buf))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
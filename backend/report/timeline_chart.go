@@ -0,0 +1,331 @@
+package report
+
+import (
+	"time"
+)
+
+// Options.TimelineChart values.
+const (
+	TimelineChartActor = "actor" // markers colored by who acted (default)
+	TimelineChartPhase = "phase" // markers colored by lifecycle phase
+	TimelineChartOff   = "off"
+)
+
+// Chart geometry, in millimeters, shared by the PDF drawing and the HTML SVG.
+const (
+	chartBandTop  = 5.0  // top of the impact and mitigation bands, below their labels
+	chartAxis     = 26.0 // the time axis
+	chartStem     = 6.0  // height of the shortest marker stem
+	chartStagger  = 5.0  // extra stem height per label level
+	chartLevels   = 3    // label levels, so close events do not print over each other
+	chartHeight   = 33.0 // down to the tick labels
+	chartMarker   = 1.2  // marker radius
+	chartFlipLeft = 0.85 // past this point of the axis labels go left of the stem
+)
+
+// actorColors are given to actors in order of appearance, wrapping around.
+var actorColors = []Color{"#1F77B4", "#FF7F0E", "#2CA02C", "#D62728", "#9467BD", "#8C564B", "#E377C2", "#17BECF"}
+
+// Phases of the incident lifecycle, in order, and their marker colors.
+var chartPhases = []struct {
+	Name  string
+	Color Color
+}{
+	{"Before impact", "#8C8C8C"},
+	{"Impact", "#C00000"},
+	{"Response", "#DE7800"},
+	{"Recovery", "#2E7D32"},
+	{"Resolved", "#1F77B4"},
+}
+
+// Band fills.
+const (
+	impactBand     Color = "#FDE2E1"
+	mitigationBand Color = "#E2F2E3"
+)
+
+// timeBand is a window of the incident shaded on charts.
+type timeBand struct {
+	From, To time.Time
+	Label    string
+	Fill     Color
+}
+
+// incidentBands are the impact window, from the impact start until
+// mitigation (or resolution), and the mitigation window, from mitigation
+// until resolution. ms is as returned by lifecycle.
+func incidentBands(ms []milestone, lang string) []timeBand {
+	impactStart, mitigated, resolved := ms[0], ms[3], ms[4]
+	var bands []timeBand
+	if impactStart.OK {
+		to := resolved
+		if mitigated.OK {
+			to = mitigated
+		}
+		if to.OK && to.Time.After(impactStart.Time) {
+			bands = append(bands, timeBand{impactStart.Time, to.Time, tr(lang, "Impact"), impactBand})
+		}
+	}
+	if mitigated.OK && resolved.OK && resolved.Time.After(mitigated.Time) {
+		bands = append(bands, timeBand{mitigated.Time, resolved.Time, tr(lang, "Mitigation"), mitigationBand})
+	}
+	return bands
+}
+
+// timelineChart is the incident drawn on a horizontal time axis. Positions
+// are fractions of the axis, from 0 at its start to 1 at its end, so each
+// format scales them to its own width.
+type timelineChart struct {
+	Events []chartEvent
+	Bands  []chartBand
+	Ticks  []chartTick
+	Legend []chartLegend
+}
+
+type chartEvent struct {
+	At    float64
+	Label string // the entry time as written
+	Color Color
+	Level int // label level, from 0 (lowest) to chartLevels-1
+}
+
+type chartBand struct {
+	From, To float64
+	Label    string
+	Fill     Color
+}
+
+type chartTick struct {
+	At    float64
+	Label string
+}
+
+type chartLegend struct {
+	Label string
+	Color Color
+}
+
+// tickStep is an interval between axis ticks: a duration, or a number of
+// calendar months for the longer axes.
+type tickStep struct {
+	d      time.Duration
+	months int
+}
+
+// approx is the length of the step, counting months as their average.
+func (s tickStep) approx() time.Duration {
+	if s.months > 0 {
+		return time.Duration(s.months) * 2629746 * time.Second // 30.436875 days
+	}
+	return s.d
+}
+
+const day = 24 * time.Hour
+
+// tickSteps are the candidate intervals between axis ticks. Beyond the last
+// one, steps are 1, 2 or 5 times a power of ten years.
+var tickSteps = []tickStep{
+	{d: time.Minute}, {d: 2 * time.Minute}, {d: 5 * time.Minute}, {d: 10 * time.Minute}, {d: 15 * time.Minute}, {d: 30 * time.Minute},
+	{d: time.Hour}, {d: 2 * time.Hour}, {d: 3 * time.Hour}, {d: 6 * time.Hour}, {d: 12 * time.Hour},
+	{d: day}, {d: 2 * day}, {d: 7 * day},
+	{months: 1}, {months: 3}, {months: 6}, {months: 12},
+}
+
+// maxTicks bounds the number of tick labels so they do not run into each other.
+const maxTicks = 8
+
+// buildTimelineChart lays out the timeline of data. Entry times are read
+// like milestones: full datetimes, or HH:MM on the incident's start date,
+// rolling over to the next day when a time falls before the previous entry.
+// Entries whose time cannot be read are left off the chart; ok is false when
+// none can, or when the chart is turned off.
+func buildTimelineChart(data PostmortemData) (chart timelineChart, ok bool) {
+	if data.Options.TimelineChart == TimelineChartOff {
+		return chart, false
+	}
+	type event struct {
+		at    time.Time
+		entry TimelineEntry
+	}
+	var events []event
+	for i, at := range entryTimes(data) {
+		if !at.IsZero() {
+			events = append(events, event{at, data.Timeline[i]})
+		}
+	}
+	if len(events) == 0 {
+		return chart, false
+	}
+
+	ms := lifecycle(data)
+	impactStart, detected, mitigated, resolved := ms[0], ms[1], ms[3], ms[4]
+
+	// The axis spans every event and milestone, with a little room at both ends.
+	first, last := events[0].at, events[0].at
+	span := func(t time.Time) {
+		if t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	for _, e := range events {
+		span(e.at)
+	}
+	for _, m := range []milestone{impactStart, detected, mitigated, resolved} {
+		if m.OK {
+			span(m.Time)
+		}
+	}
+	if !last.After(first) {
+		first, last = first.Add(-30*time.Minute), last.Add(30*time.Minute)
+	}
+	pad := last.Sub(first) / 25
+	first, last = first.Add(-pad), last.Add(pad)
+	at := func(t time.Time) float64 {
+		return float64(t.Sub(first)) / float64(last.Sub(first))
+	}
+
+	for _, b := range incidentBands(ms, data.Lang) {
+		chart.Bands = append(chart.Bands, chartBand{at(b.From), at(b.To), b.Label, b.Fill})
+	}
+
+	// Colors and legend, by phase or by actor in order of appearance.
+	phaseOf := func(t time.Time) int {
+		phase := 0
+		if !impactStart.OK {
+			phase = 1
+		}
+		for i, m := range []milestone{impactStart, detected, mitigated, resolved} {
+			if m.OK && !t.Before(m.Time) {
+				phase = i + 1
+			}
+		}
+		return phase
+	}
+	actors := map[string]Color{}
+	usedPhases := make([]bool, len(chartPhases))
+	for i, e := range events {
+		ev := chartEvent{At: at(e.at), Label: e.entry.Time, Level: i % chartLevels}
+		if data.Options.TimelineChart == TimelineChartPhase {
+			p := phaseOf(e.at)
+			ev.Color = chartPhases[p].Color
+			usedPhases[p] = true
+		} else {
+			actor := e.entry.Actor
+			if actor == "" {
+				actor = "-"
+			}
+			c, seen := actors[actor]
+			if !seen {
+				c = actorColors[len(actors)%len(actorColors)]
+				actors[actor] = c
+				chart.Legend = append(chart.Legend, chartLegend{actor, c})
+			}
+			ev.Color = c
+		}
+		chart.Events = append(chart.Events, ev)
+	}
+	for i, used := range usedPhases {
+		if used {
+			chart.Legend = append(chart.Legend, chartLegend{tr(data.Lang, chartPhases[i].Name), chartPhases[i].Color})
+		}
+	}
+
+	chart.Ticks = axisTicks(first, last, data.Lang)
+	return chart, true
+}
+
+// entryTimes resolves the time of each timeline entry, or the zero time for
+// the ones that cannot be read.
+func entryTimes(data PostmortemData) []time.Time {
+	clock := newClockReader(data)
+	times := make([]time.Time, len(data.Timeline))
+	for i, entry := range data.Timeline {
+		if t, ok := clock.read(entry.Time); ok {
+			times[i] = t
+		}
+	}
+	return times
+}
+
+// axisTicks places ticks on round times between first and last, using the
+// smallest step that keeps them under maxTicks. When the axis crosses
+// midnight the labels carry the date; with steps of a day or more they only
+// carry the date, the month or the year.
+func axisTicks(first, last time.Time, lang string) []chartTick {
+	step := tickStepFor(last.Sub(first))
+	layout := "15:04"
+	switch {
+	case step.months >= 12:
+		layout = "2006"
+	case step.months > 0:
+		layout = "2006-01"
+		if lang == "pt" {
+			layout = "01/2006"
+		}
+	case step.d >= day && first.Year() != last.Year():
+		layout = "2006-01-02"
+		if lang == "pt" {
+			layout = "02/01/2006"
+		}
+	case step.d >= day:
+		layout = "01-02"
+		if lang == "pt" {
+			layout = "02/01"
+		}
+	case first.Format("2006-01-02") != last.Format("2006-01-02"):
+		layout = "01-02 15:04"
+		if lang == "pt" {
+			layout = "02/01 15:04"
+		}
+	}
+
+	var next func(i int) time.Time
+	if step.months > 0 {
+		// Calendar steps start on the first of a month that is a multiple
+		// of the step, counted from January of year zero.
+		months := (first.Year()*12 + int(first.Month()) - 1) / step.months * step.months
+		next = func(i int) time.Time {
+			return time.Date(0, time.Month(months+i*step.months+1), 1, 0, 0, 0, 0, first.Location())
+		}
+	} else {
+		// Truncate works on absolute time, so round in the axis' own zone.
+		_, offset := first.Zone()
+		shift := time.Duration(offset) * time.Second
+		start := first.Add(shift).Truncate(step.d).Add(-shift)
+		next = func(i int) time.Time { return start.Add(time.Duration(i) * step.d) }
+	}
+
+	var ticks []chartTick
+	for i := 0; len(ticks) < maxTicks; i++ {
+		t := next(i)
+		if t.After(last) {
+			break
+		}
+		if t.Before(first) {
+			continue
+		}
+		ticks = append(ticks, chartTick{float64(t.Sub(first)) / float64(last.Sub(first)), t.Format(layout)})
+	}
+	return ticks
+}
+
+// tickStepFor is the smallest step that puts fewer than maxTicks ticks on
+// an axis spanning span.
+func tickStepFor(span time.Duration) tickStep {
+	for _, s := range tickSteps {
+		if span/s.approx() < maxTicks {
+			return s
+		}
+	}
+	year := tickStep{months: 12}.approx()
+	for years := 10; ; years *= 10 {
+		for _, n := range []int{1, 2, 5} {
+			if span/(year*time.Duration(n*years/10)) < maxTicks {
+				return tickStep{months: 12 * n * years / 10}
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chartData() PostmortemData {
+	return PostmortemData{
+		Title:     "Checkout API Failure",
+		Date:      "2024-05-01",
+		StartTime: "23:00",
+		EndTime:   "01:00",
+		Milestones: Milestones{
+			ImpactStart: "23:00",
+			Detected:    "23:10",
+			Mitigated:   "00:00",
+			Resolved:    "01:00",
+		},
+		Timeline: []TimelineEntry{
+			{ID: "t1", Time: "23:10", Actor: "Monitoring"},
+			{ID: "t2", Time: "23:25", Actor: "SRE"},
+			{ID: "t3", Time: "someday", Actor: "SRE"},
+			{ID: "t4", Time: "00:30", Actor: "SRE"},
+		},
+		Lang: "en",
+	}
+}
+
+func TestEntryTimes(t *testing.T) {
+	times := entryTimes(chartData())
+	require.Len(t, times, 4)
+	assert.Equal(t, "2024-05-01 23:10", times[0].Format("2006-01-02 15:04"))
+	assert.True(t, times[2].IsZero(), "unreadable times are left out")
+	assert.Equal(t, "2024-05-02 00:30", times[3].Format("2006-01-02 15:04"), "times before the previous entry roll over")
+}
+
+func TestAxisTicks(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		require.NoError(t, err)
+		return v
+	}
+	labels := func(ticks []chartTick) []string {
+		var out []string
+		for _, tick := range ticks {
+			out = append(out, tick.Label)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"10:00", "10:10", "10:20", "10:30", "10:40", "10:50", "11:00"}, labels(axisTicks(at("2024-05-01 10:00"), at("2024-05-01 11:00"), "en")))
+	assert.Equal(t, []string{"05-06", "05-13", "05-20", "05-27"}, labels(axisTicks(at("2024-05-01 00:00"), at("2024-05-31 00:00"), "en")))
+	assert.Equal(t, []string{"04/2024", "07/2024", "10/2024", "01/2025", "04/2025"}, labels(axisTicks(at("2024-03-15 00:00"), at("2025-06-01 00:00"), "pt")))
+	assert.Equal(t, []string{"1900", "1950", "2000", "2050", "2100"}, labels(axisTicks(at("1900-01-01 00:00"), at("2100-01-01 00:00"), "en")))
+
+	for _, span := range []time.Duration{time.Minute, 40 * time.Hour, 400 * 24 * time.Hour, 290 * 365 * 24 * time.Hour} {
+		first := at("1900-01-01 00:00")
+		ticks := axisTicks(first, first.Add(span), "en")
+		assert.NotEmpty(t, ticks, span)
+		assert.LessOrEqual(t, len(ticks), maxTicks, span)
+	}
+}
+
+func TestBuildTimelineChart(t *testing.T) {
+	data := chartData()
+	chart, ok := buildTimelineChart(data)
+	require.True(t, ok)
+
+	require.Len(t, chart.Events, 3)
+	for i := 1; i < len(chart.Events); i++ {
+		assert.Less(t, chart.Events[i-1].At, chart.Events[i].At)
+	}
+	assert.Equal(t, []chartLegend{{"Monitoring", actorColors[0]}, {"SRE", actorColors[1]}}, chart.Legend)
+	assert.Equal(t, actorColors[1], chart.Events[2].Color)
+
+	require.Len(t, chart.Bands, 2)
+	assert.Equal(t, "Impact", chart.Bands[0].Label)
+	assert.Equal(t, "Mitigation", chart.Bands[1].Label)
+	assert.Equal(t, chart.Bands[0].To, chart.Bands[1].From, "impact ends when mitigation starts")
+
+	require.NotEmpty(t, chart.Ticks)
+	assert.Equal(t, "05-01 23:00", chart.Ticks[0].Label, "the axis crosses midnight, so ticks carry the date")
+	for _, tick := range chart.Ticks {
+		assert.True(t, tick.At >= 0 && tick.At <= 1, tick.Label)
+	}
+
+	data.Options.TimelineChart = TimelineChartPhase
+	chart, _ = buildTimelineChart(data)
+	assert.Equal(t, []chartLegend{{"Response", "#DE7800"}, {"Recovery", "#2E7D32"}}, chart.Legend)
+
+	data.Options.TimelineChart = TimelineChartOff
+	_, ok = buildTimelineChart(data)
+	assert.False(t, ok)
+
+	_, ok = buildTimelineChart(PostmortemData{Timeline: []TimelineEntry{{Time: "TBD"}}})
+	assert.False(t, ok, "no readable times, no chart")
+}
+
+func TestTimelineChartRenderers(t *testing.T) {
+	data := chartData()
+
+	t.Run("pdf", func(t *testing.T) {
+		chart, _ := buildTimelineChart(data)
+		out := drawPDF(t, func(w *pdfWriter) {
+			w.pdf.SetY(250)
+			w.timelineChart(chart)
+			assert.Equal(t, 2, w.pdf.PageNo(), "the chart moves to the next page whole")
+		})
+		assert.Contains(t, out, "0.992 0.886 0.882 rg", "the impact band is shaded")
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+		out := buf.String()
+		assert.Contains(t, out, `<figure class="timeline-chart">`)
+		assert.Contains(t, out, `<text class="band-label"`)
+		assert.Contains(t, out, `fill="#FDE2E1"`)
+		assert.Contains(t, out, `text-anchor="middle">05-01 23:00</text>`)
+		assert.Contains(t, out, `<span class="dot" style="background: #1F77B4"></span>Monitoring</li>`)
+
+		data.Options.TimelineChart = TimelineChartOff
+		buf.Reset()
+		require.NoError(t, HTMLRenderer{}.Render(context.Background(), data, &buf))
+		assert.NotContains(t, buf.String(), "timeline-chart\">")
+	})
+}
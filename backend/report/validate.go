@@ -0,0 +1,288 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Validation error codes.
+const (
+	CodeRequired        = "required"
+	CodeInvalidSeverity = "invalid_severity"
+	CodeInvalidDate     = "invalid_date"
+	CodeInvalidTime     = "invalid_time"
+	CodeInvalidImage    = "invalid_image"
+	CodeInvalidLang     = "invalid_lang"
+	CodeInvalidStatus   = "invalid_status"
+	CodeInvalidDateTime = "invalid_datetime"
+	CodeInvalidTimezone = "invalid_timezone"
+	CodeEndBeforeStart  = "end_before_start"
+	CodeMilestoneOrder  = "milestone_order"
+	CodeInvalidLayout   = "invalid_layout"
+	CodeInvalidColor    = "invalid_color"
+	CodeInvalidPage     = "invalid_page"
+	CodeInvalidChart    = "invalid_chart"
+	CodeInvalidCSV      = "invalid_csv"
+	CodeInvalidSize     = "invalid_size"
+	CodeInvalidDPI      = "invalid_dpi"
+	CodeInvalidQuality  = "invalid_quality"
+	CodeImageTooLarge   = "image_too_large"
+)
+
+// FieldError describes one invalid field. Field is the JSON path of the
+// value, e.g. "actions[2].due"; Message is localized to the report language.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is the list of problems found in a postmortem.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	parts := make([]string, len(v))
+	for i, e := range v {
+		parts[i] = fmt.Sprintf("%s: %s", e.Field, e.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+var validationMessages = map[string]string{
+	CodeRequired:        "This field is required.",
+	CodeInvalidSeverity: "Use one of SEV-1, SEV-2, SEV-3 or SEV-4.",
+	CodeInvalidDate:     "Use the YYYY-MM-DD date format.",
+	CodeInvalidTime:     "Use the HH:MM 24-hour time format.",
+	CodeInvalidImage:    "The image is not a valid PNG, JPEG, GIF, WebP, BMP or TIFF data URL or asset reference.",
+	CodeInvalidLang:     "Use \"pt\" or \"en\".",
+	CodeInvalidStatus:   "Use draft, in_review, approved or published.",
+	CodeInvalidDateTime: "Use an ISO 8601 date and time, e.g. 2024-05-01T23:10:00-03:00.",
+	CodeInvalidTimezone: "Use an IANA timezone name, e.g. America/Sao_Paulo.",
+	CodeEndBeforeStart:  "The end must not be before the start.",
+	CodeMilestoneOrder:  "Milestones must follow impact start, detected, acknowledged, mitigated, resolved.",
+	CodeInvalidLayout:   "Use \"cards\" or \"table\".",
+	CodeInvalidColor:    "Use a #RRGGBB hex color.",
+	CodeInvalidPage:     "Use A4, Letter, Legal, A3 or A5, in portrait or landscape orientation.",
+	CodeInvalidChart:    "Use \"actor\", \"phase\" or \"off\".",
+	CodeInvalidCSV:      "Use a time column followed by numeric value columns, one row per time.",
+	CodeInvalidSize:     "Use \"full\", \"half\" or \"third\".",
+	CodeInvalidDPI:      "Use a DPI between 72 and 600.",
+	CodeInvalidQuality:  "Use a quality between 1 and 100.",
+	CodeImageTooLarge:   "The image is larger than 50 megapixels.",
+}
+
+type validator struct {
+	lang string
+	errs ValidationErrors
+}
+
+func (v *validator) add(field, code string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: tr(v.lang, validationMessages[code])})
+}
+
+func (v *validator) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, CodeRequired)
+		return false
+	}
+	return true
+}
+
+func (v *validator) date(field, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		v.add(field, CodeInvalidDate)
+	}
+}
+
+func (v *validator) clock(field, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := time.Parse("15:04", value); err != nil {
+		v.add(field, CodeInvalidTime)
+	}
+}
+
+func (v *validator) dateTime(field, value string, loc *time.Location) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, ok := parseDateTime(value, loc)
+	if !ok {
+		v.add(field, CodeInvalidDateTime)
+	}
+	return t, ok
+}
+
+func (v *validator) image(field, dataURL string) {
+	if isAssetRef(dataURL) {
+		return
+	}
+	mimeType, decoded, err := parseDataURL(dataURL)
+	if err != nil || !supportedImage(mimeType) {
+		v.add(field, CodeInvalidImage)
+		return
+	}
+	if _, _, err := imageConfig(decoded); errors.Is(err, ErrImageTooLarge) {
+		v.add(field, CodeImageTooLarge)
+	}
+}
+
+// Validate checks the fields that would otherwise silently produce a broken
+// report. It returns nil when data is valid.
+func Validate(data PostmortemData) ValidationErrors {
+	v := &validator{lang: data.Lang}
+
+	switch data.Lang {
+	case "", "pt", "en":
+	default:
+		v.add("lang", CodeInvalidLang)
+	}
+
+	v.required("title", data.Title)
+	if v.required("severity", data.Severity) {
+		switch strings.ToUpper(strings.TrimSpace(data.Severity)) {
+		case "SEV-1", "SEV-2", "SEV-3", "SEV-4":
+		default:
+			v.add("severity", CodeInvalidSeverity)
+		}
+	}
+	v.date("date", data.Date)
+	v.clock("startTime", data.StartTime)
+	v.clock("endTime", data.EndTime)
+	if data.Timezone != "" {
+		if _, err := time.LoadLocation(data.Timezone); err != nil {
+			v.add("timezone", CodeInvalidTimezone)
+		}
+	}
+	loc := incidentLocation(data)
+	startAt, okStart := v.dateTime("startAt", data.StartAt, loc)
+	endAt, okEnd := v.dateTime("endAt", data.EndAt, loc)
+	if okStart && okEnd && endAt.Before(startAt) {
+		v.add("endAt", CodeEndBeforeStart)
+	}
+
+	var prev time.Time
+	for _, m := range resolveMilestones(data) {
+		field := "milestones." + m.Field
+		switch {
+		case m.Value == "":
+		case !m.OK:
+			v.add(field, CodeInvalidDateTime)
+		case !prev.IsZero() && m.Time.Before(prev):
+			v.add(field, CodeMilestoneOrder)
+		default:
+			prev = m.Time
+		}
+	}
+
+	switch data.Options.CAPALayout {
+	case "", CAPALayoutCards, CAPALayoutTable:
+	default:
+		v.add("options.capaLayout", CodeInvalidLayout)
+	}
+	switch data.Options.TimelineChart {
+	case "", TimelineChartActor, TimelineChartPhase, TimelineChartOff:
+	default:
+		v.add("options.timelineChart", CodeInvalidChart)
+	}
+	if dpi := data.Options.Images.DPI; dpi != 0 && (dpi < 72 || dpi > 600) {
+		v.add("options.images.dpi", CodeInvalidDPI)
+	}
+	if q := data.Options.Images.Quality; q != 0 && (q < 1 || q > 100) {
+		v.add("options.images.quality", CodeInvalidQuality)
+	}
+	if size, orientation := data.Options.Page.valid(); !size {
+		v.add("options.page.size", CodeInvalidPage)
+	} else if !orientation {
+		v.add("options.page.orientation", CodeInvalidPage)
+	}
+	if t := data.Options.Theme.Theme; t != nil {
+		for _, name := range t.invalidColors() {
+			v.add("options.theme.palette."+name, CodeInvalidColor)
+		}
+	}
+
+	switch data.Status {
+	case "", StatusDraft, StatusInReview, StatusApproved, StatusPublished:
+	default:
+		v.add("status", CodeInvalidStatus)
+	}
+
+	for i, entry := range data.Timeline {
+		for j, img := range entry.Images {
+			v.image(fmt.Sprintf("timeline[%d].images[%d]", i, j), img.Src)
+			if img.fraction() == 0 {
+				v.add(fmt.Sprintf("timeline[%d].images[%d].size", i, j), CodeInvalidSize)
+			}
+		}
+		for j, s := range entry.Snippets {
+			v.required(fmt.Sprintf("timeline[%d].snippets[%d].code", i, j), s.Code)
+		}
+	}
+	for _, section := range []struct {
+		field    string
+		snippets []Snippet
+	}{
+		{"summary", data.Snippets.Summary},
+		{"impact", data.Snippets.Impact},
+		{"rootCause", data.Snippets.RootCause},
+		{"detection", data.Snippets.Detection},
+		{"response", data.Snippets.Response},
+		{"comm", data.Snippets.Comm},
+	} {
+		for j, s := range section.snippets {
+			v.required(fmt.Sprintf("snippets.%s[%d].code", section.field, j), s.Code)
+		}
+	}
+
+	reader := seriesReader{data}
+	for i, c := range data.Charts {
+		v.required(fmt.Sprintf("charts[%d].title", i), c.Title)
+		series, err := c.allSeries()
+		if err != nil {
+			v.add(fmt.Sprintf("charts[%d].csv", i), CodeInvalidCSV)
+			continue
+		}
+		if len(series) == 0 {
+			v.add(fmt.Sprintf("charts[%d].series", i), CodeRequired)
+		}
+		for j, s := range series {
+			_, bad := reader.read(s)
+			switch {
+			case j >= len(c.Series):
+				// Read from CSV, which has no field per point.
+				if len(bad) > 0 {
+					v.add(fmt.Sprintf("charts[%d].csv", i), CodeInvalidDateTime)
+				}
+			case len(s.Points) == 0:
+				v.add(fmt.Sprintf("charts[%d].series[%d].points", i, j), CodeRequired)
+			default:
+				for _, k := range bad {
+					v.add(fmt.Sprintf("charts[%d].series[%d].points[%d].time", i, j, k), CodeInvalidDateTime)
+				}
+			}
+		}
+	}
+
+	for i, a := range data.Actions {
+		v.date(fmt.Sprintf("actions[%d].due", i), a.Due)
+	}
+
+	for _, b := range []struct{ field, img string }{
+		{"branding.logo", data.Branding.Logo},
+		{"branding.header", data.Branding.Header},
+		{"branding.footer", data.Branding.Footer},
+	} {
+		if b.img != "" {
+			v.image(b.field, b.img)
+		}
+	}
+
+	return v.errs
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	valid := PostmortemData{
+		Title:     "Checkout API Failure",
+		Date:      "2025-10-18",
+		Severity:  "SEV-2",
+		StartTime: "02:22",
+		EndTime:   "23:34",
+		Actions:   []Action{{Action: "Add TTL test", Due: "2025-11-01"}},
+		Timeline:  []TimelineEntry{{ID: "t1", Images: []Image{{Src: tinyPNG}}}},
+	}
+	assert.Empty(t, Validate(valid))
+
+	invalid := valid
+	invalid.Title = " "
+	invalid.Severity = "SEV-9"
+	invalid.Date = "18/10/2025"
+	invalid.StartTime = "25:99"
+	invalid.Actions = []Action{{Action: "Add TTL test", Due: "next week"}}
+	invalid.Timeline = []TimelineEntry{{ID: "t1", Images: []Image{{Src: "data:image/svg+xml;base64,PHN2Zy8+", Size: "quarter"}}, Snippets: []Snippet{{Code: "ok"}, {Title: "empty"}}}}
+	invalid.Snippets.RootCause = []Snippet{{Code: "\n"}}
+	invalid.Options.Theme = ThemeRef{Theme: &Theme{Palette: Palette{Primary: "#FFF", Accent: "#004785"}}}
+	invalid.Options.Page = PageOptions{Size: "B5"}
+	invalid.Options.TimelineChart = "severity"
+	invalid.Options.Images = ImageOptions{DPI: 20, Quality: 101}
+	invalid.Charts = []MetricChart{
+		{Series: []MetricSeries{{Name: "5xx", Points: []MetricPoint{{"23:00", 1}, {"later", 2}}}, {Name: "4xx"}}},
+		{Title: "Latency", CSV: "time,p99\n23:00,fast"},
+		{Title: "Saturation", CSV: "time,cpu\nsoon,90"},
+	}
+	invalid.Lang = "pt"
+
+	errs := Validate(invalid)
+	codes := map[string]string{}
+	for _, e := range errs {
+		codes[e.Field] = e.Code
+		assert.NotEmpty(t, e.Message)
+	}
+	assert.Equal(t, map[string]string{
+		"title":                              CodeRequired,
+		"severity":                           CodeInvalidSeverity,
+		"date":                               CodeInvalidDate,
+		"startTime":                          CodeInvalidTime,
+		"actions[0].due":                     CodeInvalidDate,
+		"timeline[0].images[0]":              CodeInvalidImage,
+		"timeline[0].images[0].size":         CodeInvalidSize,
+		"timeline[0].snippets[1].code":       CodeRequired,
+		"snippets.rootCause[0].code":         CodeRequired,
+		"options.theme.palette.primary":      CodeInvalidColor,
+		"options.page.size":                  CodeInvalidPage,
+		"options.timelineChart":              CodeInvalidChart,
+		"options.images.dpi":                 CodeInvalidDPI,
+		"options.images.quality":             CodeInvalidQuality,
+		"charts[0].title":                    CodeRequired,
+		"charts[0].series[0].points[1].time": CodeInvalidDateTime,
+		"charts[0].series[1].points":         CodeRequired,
+		"charts[1].csv":                      CodeInvalidCSV,
+		"charts[2].csv":                      CodeInvalidDateTime,
+	}, codes)
+	assert.Equal(t, "Este campo é obrigatório.", errs[0].Message)
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // IANA zones must resolve even on minimal container images
+)
+
+// dateTimeLayouts are the accepted forms of StartAt/EndAt. Layouts without an
+// offset are read in the incident's timezone.
+var dateTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// incidentLocation returns the incident's timezone, or UTC when none (or an
+// unknown one) is given.
+func incidentLocation(data PostmortemData) *time.Location {
+	if data.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(data.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseDateTime reads value using dateTimeLayouts, in loc when it has no offset.
+func parseDateTime(value string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), loc); err == nil {
+			return t.In(loc), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// clockReader reads a sequence of instants written as full datetimes, or as
+// HH:MM on the current day. The day starts as the incident's start date and
+// moves with each instant read; an HH:MM falling before the previous
+// instant is taken as the next day.
+type clockReader struct {
+	loc  *time.Location
+	day  string
+	prev time.Time
+}
+
+func newClockReader(data PostmortemData) *clockReader {
+	day := data.Date
+	if start, _, _ := incidentWindow(data); !start.IsZero() {
+		day = start.Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", day); err != nil {
+		day = "2000-01-01"
+	}
+	return &clockReader{loc: incidentLocation(data), day: day}
+}
+
+func (r *clockReader) read(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	var t time.Time
+	var ok bool
+	if _, err := time.Parse("15:04", value); err == nil {
+		t, ok = parseDateTime(r.day+"T"+value, r.loc)
+		if ok && !r.prev.IsZero() && t.Before(r.prev) {
+			t = t.AddDate(0, 0, 1)
+		}
+	} else {
+		t, ok = parseDateTime(value, r.loc)
+	}
+	if ok {
+		r.prev, r.day = t, t.Format("2006-01-02")
+	}
+	return t, ok
+}
+
+// incidentWindow resolves when the incident started and ended. Full StartAt
+// and EndAt datetimes win; otherwise Date plus the HH:MM StartTime/EndTime are
+// used, and an end time earlier than the start is taken as the next day.
+func incidentWindow(data PostmortemData) (start, end time.Time, ok bool) {
+	loc := incidentLocation(data)
+
+	var hasStart, hasEnd bool
+	if data.StartAt != "" {
+		start, hasStart = parseDateTime(data.StartAt, loc)
+	}
+	if data.EndAt != "" {
+		end, hasEnd = parseDateTime(data.EndAt, loc)
+	}
+
+	day := data.Date
+	if _, err := time.Parse("2006-01-02", day); err != nil {
+		day = "2000-01-01" // only the clock is known; any day works for the duration
+	}
+	if !hasStart && data.StartTime != "" {
+		start, hasStart = parseDateTime(day+"T"+data.StartTime, loc)
+	}
+	if !hasEnd && data.EndTime != "" {
+		endDay := day
+		if hasStart && data.StartAt != "" {
+			endDay = start.Format("2006-01-02")
+		}
+		end, hasEnd = parseDateTime(endDay+"T"+data.EndTime, loc)
+		if hasEnd && hasStart && end.Before(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+	}
+	return start, end, hasStart && hasEnd
+}
+
+// formatDuration prints d as "1d 3h 12m", leaving out the days when there are none.
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	total := int(d.Round(time.Minute) / time.Minute)
+	days, hours, minutes := total/(24*60), total/60%24, total%60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// incidentTimes is the incident window formatted for display, shared by every renderer.
+type incidentTimes struct {
+	Date     string // start date in the report language
+	Start    string // in the incident's timezone
+	StartUTC string // empty unless a timezone other than UTC is set
+	End      string
+	EndUTC   string
+	Duration string
+}
+
+// formatIncidentTimes formats the incident window for lang. Reports without
+// dates keep showing the times exactly as entered.
+func formatIncidentTimes(data PostmortemData) incidentTimes {
+	t := incidentTimes{Date: formatDate(data.Date, data.Lang), Start: data.StartTime, End: data.EndTime, Duration: data.Duration}
+	start, end, ok := incidentWindow(data)
+	if !ok {
+		return t
+	}
+	t.Duration = formatDuration(end.Sub(start))
+	if data.StartAt != "" {
+		t.Date = formatDate(start.Format("2006-01-02"), data.Lang)
+	}
+
+	hasDates := data.StartAt != "" || data.EndAt != "" || data.Date != ""
+	if !hasDates {
+		return t
+	}
+	// Without a timezone the times are whatever the author typed, so no zone is claimed.
+	zoned := data.Timezone != ""
+	t.Start = formatDateTime(start, data.Lang, zoned)
+	t.End = formatDateTime(end, data.Lang, zoned)
+	if zoned && incidentLocation(data) != time.UTC {
+		t.StartUTC = formatDateTime(start.UTC(), data.Lang, true)
+		t.EndUTC = formatDateTime(end.UTC(), data.Lang, true)
+	}
+	return t
+}
+
+// formatDateTime prints t using the date order of lang, with its zone
+// abbreviation when zoned is set.
+func formatDateTime(t time.Time, lang string, zoned bool) string {
+	layout := "2006-01-02 15:04"
+	if lang == "pt" {
+		layout = "02/01/2006 15:04"
+	}
+	if zoned {
+		layout += " MST"
+	}
+	return t.Format(layout)
+}
+
+// nonEmpty drops empty strings, keeping the order of the rest.
+func nonEmpty(values ...string) []string {
+	out := values[:0:0]
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// withUTC joins a local time and its UTC equivalent on one line.
+func withUTC(local, utc string) string {
+	if utc == "" {
+		return local
+	}
+	return fmt.Sprintf("%s (%s)", local, utc)
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatIncidentTimes(t *testing.T) {
+	// The old HH:MM-only calculation reported 0h 0m for anything crossing midnight.
+	crossing := formatIncidentTimes(PostmortemData{Date: "2024-05-01", StartTime: "23:10", EndTime: "01:05"})
+	assert.Equal(t, "1h 55m", crossing.Duration)
+	assert.Equal(t, "2024-05-01 23:10", crossing.Start)
+	assert.Equal(t, "2024-05-02 01:05", crossing.End)
+
+	multiDay := formatIncidentTimes(PostmortemData{
+		StartAt: "2024-05-01T22:00:00Z",
+		EndAt:   "2024-05-03T01:12:00Z",
+	})
+	assert.Equal(t, "1d 3h 12m", multiDay.Duration)
+
+	zoned := formatIncidentTimes(PostmortemData{
+		Lang:     "pt",
+		StartAt:  "2024-05-01T23:10",
+		EndAt:    "2024-05-02T01:05",
+		Timezone: "America/Sao_Paulo",
+	})
+	assert.Equal(t, "1h 55m", zoned.Duration)
+	assert.Equal(t, "01/05/2024 23:10 -03", zoned.Start)
+	assert.Equal(t, "02/05/2024 02:10 UTC", zoned.StartUTC)
+	assert.Equal(t, "01/05/2024", zoned.Date)
+
+	legacy := formatIncidentTimes(PostmortemData{StartTime: "02:22", EndTime: "23:34"})
+	assert.Equal(t, "21h 12m", legacy.Duration)
+	assert.Equal(t, "02:22", legacy.Start)
+	assert.Empty(t, legacy.StartUTC)
+}
+
+func TestValidateIncidentWindow(t *testing.T) {
+	data := PostmortemData{
+		Title:    "Checkout API Failure",
+		Severity: "SEV-2",
+		StartAt:  "2024-05-02T10:00:00Z",
+		EndAt:    "2024-05-01T10:00:00Z",
+		Timezone: "Mars/Olympus_Mons",
+	}
+	codes := map[string]string{}
+	for _, e := range Validate(data) {
+		codes[e.Field] = e.Code
+	}
+	assert.Equal(t, map[string]string{
+		"timezone": CodeInvalidTimezone,
+		"endAt":    CodeEndBeforeStart,
+	}, codes)
+
+	data.EndAt = "tomorrow"
+	data.Timezone = "Europe/Lisbon"
+	errs := Validate(data)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, FieldError{Field: "endAt", Code: CodeInvalidDateTime, Message: validationMessages[CodeInvalidDateTime]}, errs[0])
+	}
+}
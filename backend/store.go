@@ -0,0 +1,356 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"postmortem-generator/report"
+)
+
+// ErrNotFound is returned when a postmortem ID does not exist in the store.
+var ErrNotFound = errors.New("postmortem not found")
+
+// ErrRevisionNotFound is returned when a revision number does not exist for a postmortem.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+var idPattern = regexp.MustCompile(`^[a-f0-9]{16}$`)
+
+// StoredPostmortem is a PostmortemData persisted together with its metadata.
+type StoredPostmortem struct {
+	ID            string                `json:"id"`
+	Revision      int                   `json:"revision"`
+	Status        string                `json:"status"`
+	Approvers     []string              `json:"approvers"`
+	StatusHistory []StatusChange        `json:"statusHistory"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
+	Data          report.PostmortemData `json:"data"`
+}
+
+// Revision is an immutable snapshot written on every create or update.
+type Revision struct {
+	Number    int                   `json:"number"`
+	Author    string                `json:"author"`
+	CreatedAt time.Time             `json:"createdAt"`
+	Data      report.PostmortemData `json:"data"`
+}
+
+// RevisionInfo describes a revision without its content.
+type RevisionInfo struct {
+	Number    int       `json:"number"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PostmortemSummary is the lightweight view returned when listing postmortems,
+// so the list endpoint does not ship every embedded image.
+type PostmortemSummary struct {
+	ID        string    `json:"id"`
+	Revision  int       `json:"revision"`
+	Status    string    `json:"status"`
+	Title     string    `json:"title"`
+	Date      string    `json:"date"`
+	Severity  string    `json:"severity"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// FileStore keeps each postmortem as JSON under <dir>/<id>/postmortem.json,
+// with its revisions under <dir>/<id>/revisions/.
+type FileStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewFileStore opens (and creates if needed) a store rooted at dir.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *FileStore) recordPath(id string) string {
+	return filepath.Join(s.dir, id, "postmortem.json")
+}
+
+func (s *FileStore) revisionsDir(id string) string {
+	return filepath.Join(s.dir, id, "revisions")
+}
+
+func (s *FileStore) revisionPath(id string, n int) string {
+	return filepath.Join(s.revisionsDir(id), fmt.Sprintf("%06d.json", n))
+}
+
+// Create stores data under a freshly generated ID as revision 1.
+func (s *FileStore) Create(data report.PostmortemData, author string) (*StoredPostmortem, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	pm := &StoredPostmortem{
+		ID:        id,
+		Revision:  1,
+		Status:    report.StatusDraft,
+		Approvers: []string{},
+		StatusHistory: []StatusChange{
+			{To: report.StatusDraft, By: author, At: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+		Data:      data,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(s.revisionsDir(id), 0o755); err != nil {
+		return nil, err
+	}
+	if err := s.save(pm, author); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// save writes the revision snapshot first, then the current record pointing at it.
+func (s *FileStore) save(pm *StoredPostmortem, author string) error {
+	rev := Revision{Number: pm.Revision, Author: author, CreatedAt: pm.UpdatedAt, Data: pm.Data}
+	path := s.revisionPath(pm.ID, rev.Number)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("revision %d of %s already exists", rev.Number, pm.ID)
+	}
+	if err := writeJSONFile(path, rev); err != nil {
+		return err
+	}
+	return writeJSONFile(s.recordPath(pm.ID), pm)
+}
+
+// Get loads the postmortem with the given ID.
+func (s *FileStore) Get(id string) (*StoredPostmortem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.load(id)
+}
+
+func (s *FileStore) load(id string) (*StoredPostmortem, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	var pm StoredPostmortem
+	if err := readJSONFile(s.recordPath(id), &pm); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if pm.Status == "" {
+		pm.Status = report.StatusDraft
+	}
+	return &pm, nil
+}
+
+// Update replaces the content of an existing postmortem, keeping its ID and
+// creation time, and records the change as a new revision by author.
+// Editing an approved postmortem sends it back to draft; published ones are locked.
+func (s *FileStore) Update(id string, data report.PostmortemData, author string) (*StoredPostmortem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pm, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if pm.Status == report.StatusPublished {
+		return nil, ErrPublished
+	}
+	now := time.Now().UTC()
+	if pm.Status == report.StatusApproved {
+		pm.StatusHistory = append(pm.StatusHistory, StatusChange{
+			From:    pm.Status,
+			To:      report.StatusDraft,
+			By:      author,
+			Comment: "content changed after approval",
+			At:      now,
+		})
+		pm.Status = report.StatusDraft
+	}
+	pm.Data = data
+	pm.Revision++
+	pm.UpdatedAt = now
+	if err := s.save(pm, author); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// Transition moves a postmortem to another lifecycle state on behalf of by.
+func (s *FileStore) Transition(id, to, by, comment string) (*StoredPostmortem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pm, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(pm.Status, to, by, pm.Approvers); err != nil {
+		return nil, err
+	}
+	pm.StatusHistory = append(pm.StatusHistory, StatusChange{
+		From:    pm.Status,
+		To:      to,
+		By:      by,
+		Comment: comment,
+		At:      time.Now().UTC(),
+	})
+	pm.Status = to
+	if err := writeJSONFile(s.recordPath(id), pm); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// SetApprovers replaces the list of people allowed to approve a postmortem.
+func (s *FileStore) SetApprovers(id string, approvers []string) (*StoredPostmortem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pm, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if pm.Status == report.StatusPublished {
+		return nil, ErrPublished
+	}
+	pm.Approvers = approvers
+	if err := writeJSONFile(s.recordPath(id), pm); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// Revisions lists every revision of a postmortem, oldest first.
+func (s *FileStore) Revisions(id string) ([]RevisionInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pm, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]RevisionInfo, 0, pm.Revision)
+	for n := 1; n <= pm.Revision; n++ {
+		rev, err := s.loadRevision(id, n)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, RevisionInfo{Number: rev.Number, Author: rev.Author, CreatedAt: rev.CreatedAt})
+	}
+	return list, nil
+}
+
+// Revision loads revision n of a postmortem.
+func (s *FileStore) Revision(id string, n int) (*Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, err := s.load(id); err != nil {
+		return nil, err
+	}
+	return s.loadRevision(id, n)
+}
+
+func (s *FileStore) loadRevision(id string, n int) (*Revision, error) {
+	var rev Revision
+	if err := readJSONFile(s.revisionPath(id, n), &rev); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// Delete removes a postmortem and everything stored alongside it.
+func (s *FileStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.load(id); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(s.dir, id))
+}
+
+// List returns a summary of every stored postmortem, most recently updated first.
+func (s *FileStore) List() ([]PostmortemSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	list := []PostmortemSummary{}
+	for _, e := range entries {
+		if !e.IsDir() || !idPattern.MatchString(e.Name()) {
+			continue
+		}
+		pm, err := s.load(e.Name())
+		if err != nil {
+			continue
+		}
+		list = append(list, PostmortemSummary{
+			ID:        pm.ID,
+			Revision:  pm.Revision,
+			Status:    pm.Status,
+			Title:     pm.Data.Title,
+			Date:      pm.Data.Date,
+			Severity:  pm.Data.Severity,
+			CreatedAt: pm.CreatedAt,
+			UpdatedAt: pm.UpdatedAt,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].UpdatedAt.After(list[j].UpdatedAt) })
+	return list, nil
+}
+
+func readJSONFile(path string, v interface{}) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// writeJSONFile writes v to path through a temp file so readers never see a partial record.
+func writeJSONFile(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
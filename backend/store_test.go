@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"postmortem-generator/report"
+)
+
+func TestFileStoreCRUD(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	created, err := store.Create(report.PostmortemData{Title: "Checkout API Failure", Severity: "SEV-2"}, "ana")
+	require.NoError(t, err)
+	assert.Len(t, created.ID, 16)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	found, err := store.Get(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Checkout API Failure", found.Data.Title)
+
+	updated, err := store.Update(created.ID, report.PostmortemData{Title: "Checkout API Outage"}, "bruno")
+	require.NoError(t, err)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	assert.Equal(t, "Checkout API Outage", updated.Data.Title)
+
+	assert.Equal(t, 2, updated.Revision)
+
+	list, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, created.ID, list[0].ID)
+
+	require.NoError(t, store.Delete(created.ID))
+	_, err = store.Get(created.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFileStoreRejectsUnknownIDs(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.Get("../../etc/passwd")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFileStoreKeepsRevisions(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	created, err := store.Create(report.PostmortemData{Title: "Draft"}, "ana")
+	require.NoError(t, err)
+	_, err = store.Update(created.ID, report.PostmortemData{Title: "Final"}, "bruno")
+	require.NoError(t, err)
+
+	revs, err := store.Revisions(created.ID)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	assert.Equal(t, "ana", revs[0].Author)
+	assert.Equal(t, "bruno", revs[1].Author)
+
+	first, err := store.Revision(created.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Draft", first.Data.Title)
+
+	_, err = store.Revision(created.ID, 3)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+}
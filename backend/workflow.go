@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"postmortem-generator/report"
+)
+
+var (
+	// ErrInvalidTransition is returned when the requested state cannot be reached from the current one.
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrNotApprover is returned when someone outside the approver list tries to approve.
+	ErrNotApprover = errors.New("only listed approvers can approve this postmortem")
+	// ErrPublished is returned when trying to edit a postmortem that was already published.
+	ErrPublished = errors.New("published postmortems cannot be edited")
+)
+
+// allowedTransitions lists, for each state, the states it may move to.
+var allowedTransitions = map[string][]string{
+	report.StatusDraft:     {report.StatusInReview},
+	report.StatusInReview:  {report.StatusDraft, report.StatusApproved},
+	report.StatusApproved:  {report.StatusDraft, report.StatusPublished},
+	report.StatusPublished: {},
+}
+
+// StatusChange records one move through the lifecycle.
+type StatusChange struct {
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	By      string    `json:"by"`
+	Comment string    `json:"comment,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+func isValidStatus(status string) bool {
+	_, ok := allowedTransitions[status]
+	return ok
+}
+
+// checkTransition validates moving from one state to another on behalf of by.
+// Approving requires by to be on the approver list when one is configured.
+func checkTransition(from, to, by string, approvers []string) error {
+	if !isValidStatus(to) {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidTransition, to)
+	}
+	allowed := false
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	if to == report.StatusApproved && len(approvers) > 0 {
+		for _, a := range approvers {
+			if a == by {
+				return nil
+			}
+		}
+		return ErrNotApprover
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"postmortem-generator/report"
+)
+
+func TestCheckTransition(t *testing.T) {
+	assert.NoError(t, checkTransition(report.StatusDraft, report.StatusInReview, "ana", nil))
+	assert.ErrorIs(t, checkTransition(report.StatusDraft, report.StatusPublished, "ana", nil), ErrInvalidTransition)
+	assert.ErrorIs(t, checkTransition(report.StatusInReview, "shipped", "ana", nil), ErrInvalidTransition)
+	assert.ErrorIs(t, checkTransition(report.StatusInReview, report.StatusApproved, "ana", []string{"carla"}), ErrNotApprover)
+	assert.NoError(t, checkTransition(report.StatusInReview, report.StatusApproved, "carla", []string{"carla"}))
+	assert.ErrorIs(t, checkTransition(report.StatusPublished, report.StatusDraft, "carla", nil), ErrInvalidTransition)
+}
+
+func TestWorkflowLifecycle(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	pm, err := store.Create(report.PostmortemData{Title: "Checkout API Failure"}, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, report.StatusDraft, pm.Status)
+
+	_, err = store.SetApprovers(pm.ID, []string{"carla"})
+	require.NoError(t, err)
+	_, err = store.Transition(pm.ID, report.StatusInReview, "ana", "ready for review")
+	require.NoError(t, err)
+	pm, err = store.Transition(pm.ID, report.StatusApproved, "carla", "")
+	require.NoError(t, err)
+	assert.Equal(t, report.StatusApproved, pm.Status)
+
+	// Editing after approval invalidates it.
+	pm, err = store.Update(pm.ID, report.PostmortemData{Title: "Checkout API Outage"}, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, report.StatusDraft, pm.Status)
+
+	_, err = store.Transition(pm.ID, report.StatusInReview, "ana", "")
+	require.NoError(t, err)
+	_, err = store.Transition(pm.ID, report.StatusApproved, "carla", "")
+	require.NoError(t, err)
+	pm, err = store.Transition(pm.ID, report.StatusPublished, "carla", "")
+	require.NoError(t, err)
+	assert.Len(t, pm.StatusHistory, 7)
+
+	_, err = store.Update(pm.ID, report.PostmortemData{Title: "Too late"}, "ana")
+	assert.ErrorIs(t, err, ErrPublished)
+}